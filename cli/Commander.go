@@ -2,9 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	clp "github.com/urfave/cli"
 	"github.com/opwire/opwire-testa/lib/bootstrap"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/curl"
+	"github.com/opwire/opwire-testa/lib/engine"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
@@ -57,12 +62,74 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 			Name: "tags, g",
 			Usage: "Conditional tags for selecting tests",
 		},
+		clp.StringFlag{
+			Name: "env",
+			Usage: "Name of the environment (PDP & variables) to test against",
+		},
 		clp.BoolFlag{
 			Name: "no-color",
 			Usage: "Display output in plain text, without color",
 		},
 	}
 
+	snapshotFlags := []clp.Flag{
+		clp.StringSliceFlag{
+			Name: "excluded-headers",
+			Usage: "Response headers which are not asserted in the snapshots",
+		},
+		clp.StringSliceFlag{
+			Name: "volatile-headers",
+			Usage: "Response headers which are asserted by their types only",
+		},
+		clp.StringSliceFlag{
+			Name: "ignored-paths",
+			Usage: "Body field paths (e.g. items.*.id, **.createdAt) which are not asserted",
+		},
+		clp.StringSliceFlag{
+			Name: "volatile-paths",
+			Usage: "Body field paths which are asserted by their types only",
+		},
+		clp.StringSliceFlag{
+			Name: "body-assertions",
+			Usage: "Assertions of the body (includes, fields, is-equal-to)",
+		},
+		clp.BoolTFlag{
+			Name: "header-total",
+			Usage: "Assert the total number of response headers (--header-total=false to disable)",
+		},
+	}
+
+	transportFlags := []clp.Flag{
+		clp.StringFlag{
+			Name: "proxy",
+			Usage: "URL of the proxy (http, https or socks5)",
+		},
+		clp.StringFlag{
+			Name: "cacert",
+			Usage: "Path to the bundle of the trusted CA certificates (PEM)",
+		},
+		clp.StringFlag{
+			Name: "cert",
+			Usage: "Path to the client certificate (PEM) for mTLS",
+		},
+		clp.StringFlag{
+			Name: "key",
+			Usage: "Path to the private key (PEM) of the client certificate",
+		},
+		clp.BoolFlag{
+			Name: "insecure, k",
+			Usage: "Skip the verification of the server certificates",
+		},
+		clp.StringFlag{
+			Name: "redirects",
+			Usage: "Redirect policy: \"follow\" (default), \"none\" or the maximum number of redirects",
+		},
+		clp.BoolFlag{
+			Name: "no-http2",
+			Usage: "Disable HTTP/2",
+		},
+	}
+
 	app := clp.NewApp()
 	app.Name = "opwire-testa"
 	app.Usage = "Testing toolkit for opwire-agent"
@@ -73,15 +140,65 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 			Name: "run",
 			Aliases: []string{"start"},
 			Usage: "Run tests",
-			Flags: append([]clp.Flag{}, testSourceFlags...),
+			Flags: append([]clp.Flag{
+				clp.StringFlag{
+					Name: "format",
+					Usage: "Output format of the results (text, json, jsonl)",
+				},
+				clp.StringFlag{
+					Name: "report",
+					Usage: "Write a report of the results in a specific format (junit, json, jsonl)",
+				},
+				clp.StringFlag{
+					Name: "report-file",
+					Usage: "Path to the report file (requires --report)",
+				},
+				clp.IntFlag{
+					Name: "parallel",
+					Usage: "Number of test suites (and parallel testcases) running concurrently",
+				},
+				clp.BoolFlag{
+					Name: "fail-on-pending",
+					Usage: "Exit with a non-zero code if there are pending testcases",
+				},
+				clp.BoolFlag{
+					Name: "fail-on-skipped",
+					Usage: "Exit with a non-zero code if there are skipped testcases",
+				},
+				clp.BoolFlag{
+					Name: "update-snapshots",
+					Usage: "Re-record the expectations of the failed snapshot testcases from the responses",
+				},
+				clp.StringFlag{
+					Name: "openapi",
+					Usage: "OpenAPI document which the requests & responses must conform to",
+				},
+				clp.StringFlag{
+					Name: "save-results",
+					Usage: "Path to the file which keeps the captured results (e.g. for gen curl --results)",
+				},
+			}, append(snapshotFlags, testSourceFlags...)...),
 			Action: func(c *clp.Context) error {
-				o := readScriptSourceFlags(manifest, c)
+				o, err := readScriptSourceFlags(manifest, c)
+				if err != nil {
+					return err
+				}
+				o.OpenAPI = c.String("openapi")
+				o.readSnapshotFlags(c)
 				ctl, err := bootstrap.NewRunController(o)
 				if err != nil {
 					return err
 				}
-				ctl.Execute(&CmdRunFlags{})
-				return nil
+				f := new(CmdRunFlags)
+				f.OutputFormat = c.String("format")
+				f.ReportFormat = c.String("report")
+				f.ReportFile = c.String("report-file")
+				f.FailOnPending = c.Bool("fail-on-pending")
+				f.FailOnSkipped = c.Bool("fail-on-skipped")
+				f.Parallel = c.Int("parallel")
+				f.UpdateSnapshots = c.Bool("update-snapshots")
+				f.SaveResults = c.String("save-results")
+				return ctl.Execute(f)
 			},
 		},
 		{
@@ -91,7 +208,15 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 				{
 					Name: "curl",
 					Usage: "Make an HTTP request using curl syntax",
-					Flags: []clp.Flag{
+					Flags: append([]clp.Flag{
+						clp.StringFlag{
+							Name: "config-path, c",
+							Usage: "Path to configuration file",
+						},
+						clp.StringFlag{
+							Name: "command",
+							Usage: "Full curl command line (e.g. \"Copy as cURL\" of the browsers), \"-\" reads it from the standard input",
+						},
 						clp.StringFlag{
 							Name: "request, X",
 							Usage: "Specify request command to use",
@@ -116,21 +241,51 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 							Name: "snapshot",
 							Usage: "Create a snapshot of testcase",
 						},
-					},
+						clp.StringFlag{
+							Name: "append-to",
+							Usage: "Append the snapshot of testcase to a test suite file",
+						},
+						clp.StringFlag{
+							Name: "title",
+							Usage: "Title of the snapshot of testcase",
+						},
+						clp.StringSliceFlag{
+							Name: "tags",
+							Usage: "Additional tags of the snapshot of testcase",
+						},
+					}, append(snapshotFlags, transportFlags...)...),
 					Action: func(c *clp.Context) error {
 						o := &ControllerOptions{ manifest: manifest }
+						o.ConfigPath = c.String("config-path")
+						if err := o.loadConfiguration(c); err != nil {
+							return err
+						}
+						o.readSnapshotFlags(c)
+						o.readTransportFlags(c)
 						broker, err := bootstrap.NewReqController(o)
 						if err != nil {
 							return err
 						}
 						f := new(CmdReqFlags)
+						command, err := readCurlCommand(c)
+						if err != nil {
+							return err
+						}
+						f.Command = command
 						f.Method = c.String("request")
 						f.Url = c.String("url")
 						f.Header = c.StringSlice("header")
 						f.Body = c.String("data")
 						f.Format = c.String("export")
 						f.Snapshot = c.Bool("snapshot")
-						broker.Execute(f)
+						f.AppendTo = c.String("append-to")
+						f.Title = c.String("title")
+						f.Tags = c.StringSlice("tags")
+						if err := broker.Execute(f); err != nil {
+							if _, ok := err.(*bootstrap.ExitError); ok {
+								return err
+							}
+						}
 						return nil
 					},
 				},
@@ -142,16 +297,144 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 			Subcommands: []clp.Command{
 				{
 					Name: "curl",
-					Usage: "Generate curl style of a testcase",
-					Flags: append([]clp.Flag{}, testSourceFlags...),
+					Usage: "Generate the curl commands of the selected testcases",
+					Flags: append([]clp.Flag{
+						clp.StringFlag{
+							Name: "style",
+							Usage: "Quoting style of the commands: \"posix\" (default), \"powershell\" or \"cmd\"",
+						},
+						clp.BoolFlag{
+							Name: "all",
+							Usage: "Generate the commands of all of the selected testcases (in order)",
+						},
+						clp.BoolFlag{
+							Name: "resolve",
+							Usage: "Resolve the ${{...}} expressions with the variables of the environment",
+						},
+						clp.StringFlag{
+							Name: "results",
+							Usage: "Resolve the references to the captured results from a file saved by run --save-results",
+						},
+						clp.StringFlag{
+							Name: "output, o",
+							Usage: "Path to the generated file (default: the standard output)",
+						},
+						clp.BoolFlag{
+							Name: "script",
+							Usage: "Generate a runnable bash script",
+						},
+					}, testSourceFlags...),
 					Action: func(c *clp.Context) error {
-						o := readScriptSourceFlags(manifest, c)
+						o, err := readScriptSourceFlags(manifest, c)
+						if err != nil {
+							return err
+						}
 						ctl, err := bootstrap.NewGenController(o)
 						if err != nil {
 							return err
 						}
-						ctl.Execute(&CmdGenFlags{})
-						return nil
+						f := new(CmdGenFlags)
+						f.Style = c.String("style")
+						f.All = c.Bool("all")
+						f.Resolve = c.Bool("resolve")
+						f.Output = c.String("output")
+						f.Script = c.Bool("script")
+						f.Results = c.String("results")
+						return ctl.Execute(f)
+					},
+				},
+				{
+					Name: "testsuite",
+					Usage: "Generate test suites from an OpenAPI document, a HAR file or a Postman collection",
+					Flags: append([]clp.Flag{
+						clp.StringFlag{
+							Name: "from-openapi",
+							Usage: "Path to the OpenAPI document (JSON or YAML)",
+						},
+						clp.StringFlag{
+							Name: "from-har",
+							Usage: "Path to the HAR file recorded by a browser or a proxy",
+						},
+						clp.StringFlag{
+							Name: "from-postman",
+							Usage: "Path to the Postman collection (v2.1)",
+						},
+						clp.StringFlag{
+							Name: "host-pattern",
+							Usage: "Regular expression of the hosts of the HAR entries to convert",
+						},
+						clp.StringFlag{
+							Name: "path-pattern",
+							Usage: "Regular expression of the paths of the HAR entries to convert",
+						},
+						clp.StringSliceFlag{
+							Name: "drop-headers",
+							Usage: "Request headers of the HAR entries to drop (default: cookies & tracing headers)",
+						},
+						clp.StringFlag{
+							Name: "group-by",
+							Usage: "Generate a test suite per \"tag\" (default) or per \"path\"",
+						},
+						clp.StringFlag{
+							Name: "output-dir, o",
+							Usage: "Directory of the generated test suites (default: the first test directory)",
+						},
+						clp.BoolFlag{
+							Name: "force",
+							Usage: "Overwrite the existing test suite files",
+						},
+					}, append(snapshotFlags, testSourceFlags...)...),
+					Action: func(c *clp.Context) error {
+						o, err := readScriptSourceFlags(manifest, c)
+						if err != nil {
+							return err
+						}
+						o.readSnapshotFlags(c)
+						ctl, err := bootstrap.NewGenController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdGenTestSuiteFlags)
+						f.FromOpenAPI = c.String("from-openapi")
+						f.FromHAR = c.String("from-har")
+						f.FromPostman = c.String("from-postman")
+						f.GroupBy = c.String("group-by")
+						f.HostPattern = c.String("host-pattern")
+						f.PathPattern = c.String("path-pattern")
+						if c.IsSet("drop-headers") {
+							f.DropHeaders = utils.SplitAll(c.StringSlice("drop-headers"), ",")
+						}
+						f.OutputDir = c.String("output-dir")
+						f.Force = c.Bool("force")
+						return ctl.GenerateTestSuites(f)
+					},
+				},
+				{
+					Name: "postman",
+					Usage: "Export the selected testcases to a Postman collection",
+					Flags: append([]clp.Flag{
+						clp.StringFlag{
+							Name: "name",
+							Usage: "Name of the Postman collection",
+						},
+						clp.StringFlag{
+							Name: "output, o",
+							Usage: "Path to the Postman collection file (default: the standard output)",
+						},
+					}, testSourceFlags...),
+					Action: func(c *clp.Context) error {
+						o, err := readScriptSourceFlags(manifest, c)
+						if err != nil {
+							return err
+						}
+						ctl, err := bootstrap.NewGenController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdGenPostmanFlags)
+						f.Name = c.String("name")
+						f.Output = c.String("output")
+						return ctl.ExportPostman(f)
 					},
 				},
 			},
@@ -172,7 +455,30 @@ func (c *Commander) Run() error {
 	return c.app.Run(os.Args)
 }
 
-func readScriptSourceFlags(manifest Manifest, c *clp.Context) *ControllerOptions {
+// the curl command line is given by --command (or the standard input), or as the arguments after "--"
+func readCurlCommand(c *clp.Context) ([]string, error) {
+	if !c.IsSet("command") {
+		return c.Args(), nil
+	}
+	cmdline := c.String("command")
+	if cmdline == "-" {
+		content, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, bootstrap.NewExitError(bootstrap.EXIT_CODE_INVALID, fmt.Errorf("Cannot read the curl command from the standard input, error: %s", err))
+		}
+		cmdline = string(content)
+	}
+	args, err := curl.Split(cmdline)
+	if err != nil {
+		return nil, bootstrap.NewExitError(bootstrap.EXIT_CODE_INVALID, fmt.Errorf("Invalid curl command, error: %s", err))
+	}
+	if len(args) == 0 {
+		return nil, bootstrap.NewExitError(bootstrap.EXIT_CODE_INVALID, fmt.Errorf("The curl command must not be empty"))
+	}
+	return args, nil
+}
+
+func readScriptSourceFlags(manifest Manifest, c *clp.Context) (*ControllerOptions, error) {
 	o := &ControllerOptions{ manifest: manifest }
 	o.ConfigPath = c.String("config-path")
 	o.TestDirs = c.StringSlice("test-dirs")
@@ -181,7 +487,144 @@ func readScriptSourceFlags(manifest Manifest, c *clp.Context) *ControllerOptions
 	o.TestName = c.String("test-name")
 	o.Tags = c.StringSlice("tags")
 	o.NoColor = c.Bool("no-color")
-	return o
+	o.Env = c.String("env")
+	if err := o.loadConfiguration(c); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *ControllerOptions) loadConfiguration(c *clp.Context) error {
+	loader, err := config.NewLoader(nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := loader.Load(o.ConfigPath)
+	if err != nil {
+		return bootstrap.NewExitError(bootstrap.EXIT_CODE_INVALID, err)
+	}
+	env, err := loader.LoadEnvironment(cfg, o.Env)
+	if err != nil {
+		return bootstrap.NewExitError(bootstrap.EXIT_CODE_INVALID, err)
+	}
+	if env != nil {
+		if env.PDP != nil {
+			o.PDP = *env.PDP
+		}
+		o.Variables = env.GetVariables()
+	}
+	if cfg == nil {
+		return nil
+	}
+	o.ConfigPath = cfg.GetSourcePath()
+	// values from command line flags take precedence over the configuration
+	if len(o.TestDirs) == 0 {
+		o.TestDirs = cfg.TestDirs
+	}
+	if len(o.InclFiles) == 0 {
+		o.InclFiles = cfg.InclFiles
+	}
+	if len(o.ExclFiles) == 0 {
+		o.ExclFiles = cfg.ExclFiles
+	}
+	if len(o.Tags) == 0 {
+		o.Tags = cfg.Tags
+	}
+	if !c.IsSet("no-color") && cfg.NoColor != nil {
+		o.NoColor = *cfg.NoColor
+	}
+	if cfg.Http != nil {
+		if len(o.PDP) == 0 && cfg.Http.PDP != nil {
+			o.PDP = *cfg.Http.PDP
+		}
+		if len(o.Timeout) == 0 && cfg.Http.Timeout != nil {
+			o.Timeout = *cfg.Http.Timeout
+		}
+		o.Transport = &client.HttpTransportOptions{ HTTP2: cfg.Http.HTTP2 }
+		if cfg.Http.Proxy != nil {
+			o.Transport.Proxy = *cfg.Http.Proxy
+		}
+		if cfg.Http.CACert != nil {
+			o.Transport.CACert = *cfg.Http.CACert
+		}
+		if cfg.Http.ClientCert != nil {
+			o.Transport.ClientCert = *cfg.Http.ClientCert
+		}
+		if cfg.Http.ClientKey != nil {
+			o.Transport.ClientKey = *cfg.Http.ClientKey
+		}
+		if cfg.Http.InsecureSkipVerify != nil {
+			o.Transport.InsecureSkipVerify = *cfg.Http.InsecureSkipVerify
+		}
+		if cfg.Http.Redirects != nil {
+			o.Transport.Redirects = *cfg.Http.Redirects
+		}
+	}
+	if cfg.Snapshot != nil {
+		o.SnapshotProfile = &engine.GenerationProfile{
+			ExcludedHeaders: cfg.Snapshot.ExcludedHeaders,
+			VolatileHeaders: cfg.Snapshot.VolatileHeaders,
+			HeaderTotal: cfg.Snapshot.HeaderTotal,
+			IgnoredPaths: cfg.Snapshot.IgnoredPaths,
+			VolatilePaths: cfg.Snapshot.VolatilePaths,
+			BodyAssertions: cfg.Snapshot.BodyAssertions,
+		}
+	}
+	return nil
+}
+
+// the snapshot flags take precedence over the snapshot section of the configuration
+func (o *ControllerOptions) readSnapshotFlags(c *clp.Context) {
+	if o.SnapshotProfile == nil {
+		o.SnapshotProfile = &engine.GenerationProfile{}
+	}
+	p := o.SnapshotProfile
+	if c.IsSet("excluded-headers") {
+		p.ExcludedHeaders = utils.SplitAll(c.StringSlice("excluded-headers"), ",")
+	}
+	if c.IsSet("volatile-headers") {
+		p.VolatileHeaders = utils.SplitAll(c.StringSlice("volatile-headers"), ",")
+	}
+	if c.IsSet("ignored-paths") {
+		p.IgnoredPaths = utils.SplitAll(c.StringSlice("ignored-paths"), ",")
+	}
+	if c.IsSet("volatile-paths") {
+		p.VolatilePaths = utils.SplitAll(c.StringSlice("volatile-paths"), ",")
+	}
+	if c.IsSet("body-assertions") {
+		p.BodyAssertions = utils.SplitAll(c.StringSlice("body-assertions"), ",")
+	}
+	if c.IsSet("header-total") {
+		p.HeaderTotal = utils.RefOfBool(c.BoolT("header-total"))
+	}
+}
+
+func (o *ControllerOptions) readTransportFlags(c *clp.Context) {
+	if o.Transport == nil {
+		o.Transport = &client.HttpTransportOptions{}
+	}
+	t := o.Transport
+	if c.IsSet("proxy") {
+		t.Proxy = c.String("proxy")
+	}
+	if c.IsSet("cacert") {
+		t.CACert = c.String("cacert")
+	}
+	if c.IsSet("cert") {
+		t.ClientCert = c.String("cert")
+	}
+	if c.IsSet("key") {
+		t.ClientKey = c.String("key")
+	}
+	if c.Bool("insecure") {
+		t.InsecureSkipVerify = true
+	}
+	if c.IsSet("redirects") {
+		t.Redirects = c.String("redirects")
+	}
+	if c.Bool("no-http2") {
+		t.HTTP2 = utils.RefOfBool(false)
+	}
 }
 
 type Manifest interface {
@@ -198,6 +641,13 @@ type ControllerOptions struct {
 	TestName string
 	Tags []string
 	NoColor bool
+	PDP string
+	Timeout string
+	OpenAPI string
+	SnapshotProfile *engine.GenerationProfile
+	Transport *client.HttpTransportOptions
+	Env string
+	Variables map[string]string
 	manifest Manifest
 }
 
@@ -229,6 +679,34 @@ func (a *ControllerOptions) GetNoColor() bool {
 	return a.NoColor
 }
 
+func (a *ControllerOptions) GetPDP() string {
+	return a.PDP
+}
+
+func (a *ControllerOptions) GetTimeout() string {
+	return a.Timeout
+}
+
+func (a *ControllerOptions) GetOpenAPI() string {
+	return a.OpenAPI
+}
+
+func (a *ControllerOptions) GetTransport() *client.HttpTransportOptions {
+	return a.Transport
+}
+
+func (a *ControllerOptions) GetSnapshotProfile() *engine.GenerationProfile {
+	return a.SnapshotProfile
+}
+
+func (a *ControllerOptions) GetEnv() string {
+	return a.Env
+}
+
+func (a *ControllerOptions) GetVariables() map[string]string {
+	return a.Variables
+}
+
 func (a *ControllerOptions) GetVersion() string {
 	if a.manifest == nil {
 		return ""
@@ -244,12 +722,20 @@ func (a *ControllerOptions) GetRevision() string {
 }
 
 type CmdReqFlags struct {
+	Command []string
 	Method string
 	Url string
 	Header []string
 	Body string
 	Format string
 	Snapshot bool
+	AppendTo string
+	Title string
+	Tags []string
+}
+
+func (f *CmdReqFlags) GetCommand() []string {
+	return f.Command
 }
 
 func (f *CmdReqFlags) GetMethod() string {
@@ -268,6 +754,18 @@ func (f *CmdReqFlags) GetBody() string {
 	return f.Body
 }
 
+func (f *CmdReqFlags) GetAppendTo() string {
+	return f.AppendTo
+}
+
+func (f *CmdReqFlags) GetTitle() string {
+	return f.Title
+}
+
+func (f *CmdReqFlags) GetTags() []string {
+	return f.Tags
+}
+
 func (f *CmdReqFlags) GetFormat() string {
 	if f.Snapshot {
 		return "testcase"
@@ -276,7 +774,138 @@ func (f *CmdReqFlags) GetFormat() string {
 }
 
 type CmdRunFlags struct {
+	OutputFormat string
+	ReportFormat string
+	ReportFile string
+	FailOnPending bool
+	FailOnSkipped bool
+	Parallel int
+	UpdateSnapshots bool
+	SaveResults string
+}
+
+func (f *CmdRunFlags) GetUpdateSnapshots() bool {
+	return f.UpdateSnapshots
+}
+
+func (f *CmdRunFlags) GetSaveResults() string {
+	return f.SaveResults
+}
+
+func (f *CmdRunFlags) GetParallel() int {
+	return f.Parallel
+}
+
+func (f *CmdRunFlags) GetFailOnPending() bool {
+	return f.FailOnPending
+}
+
+func (f *CmdRunFlags) GetFailOnSkipped() bool {
+	return f.FailOnSkipped
+}
+
+func (f *CmdRunFlags) GetOutputFormat() string {
+	return f.OutputFormat
+}
+
+func (f *CmdRunFlags) GetReportFormat() string {
+	return f.ReportFormat
+}
+
+func (f *CmdRunFlags) GetReportFile() string {
+	return f.ReportFile
 }
 
 type CmdGenFlags struct {
+	Style string
+	All bool
+	Resolve bool
+	Output string
+	Script bool
+	Results string
+}
+
+func (f *CmdGenFlags) GetStyle() string {
+	return f.Style
+}
+
+func (f *CmdGenFlags) GetAll() bool {
+	return f.All
+}
+
+func (f *CmdGenFlags) GetResolve() bool {
+	return f.Resolve
+}
+
+func (f *CmdGenFlags) GetOutput() string {
+	return f.Output
+}
+
+func (f *CmdGenFlags) GetScript() bool {
+	return f.Script
+}
+
+func (f *CmdGenFlags) GetResults() string {
+	return f.Results
+}
+
+type CmdGenTestSuiteFlags struct {
+	FromOpenAPI string
+	FromHAR string
+	FromPostman string
+	GroupBy string
+	HostPattern string
+	PathPattern string
+	DropHeaders []string
+	OutputDir string
+	Force bool
+}
+
+func (f *CmdGenTestSuiteFlags) GetFromOpenAPI() string {
+	return f.FromOpenAPI
+}
+
+func (f *CmdGenTestSuiteFlags) GetFromHAR() string {
+	return f.FromHAR
+}
+
+func (f *CmdGenTestSuiteFlags) GetFromPostman() string {
+	return f.FromPostman
+}
+
+func (f *CmdGenTestSuiteFlags) GetGroupBy() string {
+	return f.GroupBy
+}
+
+func (f *CmdGenTestSuiteFlags) GetHostPattern() string {
+	return f.HostPattern
+}
+
+func (f *CmdGenTestSuiteFlags) GetPathPattern() string {
+	return f.PathPattern
+}
+
+func (f *CmdGenTestSuiteFlags) GetDropHeaders() []string {
+	return f.DropHeaders
+}
+
+func (f *CmdGenTestSuiteFlags) GetOutputDir() string {
+	return f.OutputDir
+}
+
+func (f *CmdGenTestSuiteFlags) GetForce() bool {
+	return f.Force
+}
+
+type CmdGenPostmanFlags struct {
+	Name string
+	Output string
+}
+
+func (f *CmdGenPostmanFlags) GetName() string {
+	return f.Name
+}
+
+func (f *CmdGenPostmanFlags) GetOutput() string {
+	return f.Output
 }
@@ -1,6 +1,6 @@
 module github.com/opwire/opwire-testa
 
-go 1.12
+go 1.13
 
 require (
 	github.com/golang/mock v1.3.1
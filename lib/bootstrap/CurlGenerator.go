@@ -0,0 +1,231 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+const (
+	CURL_STYLE_POSIX string = "posix"
+	CURL_STYLE_POWERSHELL string = "powershell"
+	CURL_STYLE_CMD string = "cmd"
+)
+
+var CURL_STYLES = []string{ CURL_STYLE_POSIX, CURL_STYLE_POWERSHELL, CURL_STYLE_CMD }
+
+type CurlGenerator struct {
+	pdp string
+	style string
+}
+
+func newCurlGenerator(pdp string, style string) (*CurlGenerator, error) {
+	style = strings.ToLower(style)
+	if len(style) == 0 {
+		style = CURL_STYLE_POSIX
+	}
+	switch(style) {
+	case CURL_STYLE_POSIX, CURL_STYLE_POWERSHELL, CURL_STYLE_CMD:
+	default:
+		return nil, fmt.Errorf("Invalid quoting style [%s], must be one of %s", style, strings.Join(CURL_STYLES, ", "))
+	}
+	return &CurlGenerator{ pdp: pdp, style: style }, nil
+}
+
+func (g *CurlGenerator) generateScriptHeader(w io.Writer) {
+	fmt.Fprintf(w, "#!/usr/bin/env bash\n")
+	fmt.Fprintf(w, "set -euo pipefail\n")
+}
+
+func (g *CurlGenerator) generateComment(w io.Writer, text string) {
+	prefix := "# "
+	if g.style == CURL_STYLE_CMD {
+		prefix = "REM "
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+func (g *CurlGenerator) generateCommand(w io.Writer, req *client.HttpRequest) error {
+	if len(req.Url) == 0 && len(req.PDP) == 0 && len(g.pdp) > 0 {
+		clone := *req
+		clone.PDP = g.pdp
+		req = &clone
+	}
+
+	if err := req.ValidateBody(); err != nil {
+		return err
+	}
+
+	lines := make([]string, 0)
+	method := req.Method
+	switch {
+	case method == "HEAD":
+		// curl waits for a response body with "--request HEAD"
+		lines = append(lines, "--head")
+	case method == "GET" && !req.HasBody():
+	case len(method) > 0:
+		lines = append(lines, "--request " + g.quote(method))
+	}
+	lines = append(lines, "--url " + g.quote(buildUrlWithPlaceholders(req)))
+	for _, header := range req.Headers {
+		if len(header.Value) == 0 {
+			// "Name;" sends a header with an empty value
+			lines = append(lines, "--header " + g.quote(header.Name + ";"))
+			continue
+		}
+		lines = append(lines, "--header " + g.quote(header.Name + ": " + header.Value))
+	}
+	if req.Timeout != nil && len(*req.Timeout) > 0 {
+		timeout, err := time.ParseDuration(*req.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("Invalid timeout [%s] of the request", *req.Timeout)
+		}
+		lines = append(lines, "--max-time " + strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64))
+	}
+	switch {
+	case len(req.BodyFile) > 0:
+		lines = append(lines, "--data-binary " + g.quote("@" + req.ResolvePath(req.BodyFile)))
+	case len(req.Form) > 0:
+		// curl encodes the value only
+		for _, field := range req.Form {
+			lines = append(lines, "--data-urlencode " + g.quote(url.QueryEscape(field.Name) + "=" + field.Value))
+		}
+	case len(req.Multipart) > 0:
+		for _, part := range req.Multipart {
+			lines = append(lines, g.formPart(req, part))
+		}
+	case len(req.Body) > 0:
+		// "--data-raw" does not read the "@file" values
+		lines = append(lines, "--data-raw " + g.quote(req.Body))
+	}
+
+	program, continuation := "curl", " \\"
+	switch(g.style) {
+	case CURL_STYLE_POWERSHELL:
+		// "curl" is an alias of Invoke-WebRequest in Windows PowerShell
+		program, continuation = "curl.exe", " `"
+	case CURL_STYLE_CMD:
+		continuation = " ^"
+	}
+	fmt.Fprint(w, program)
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s\n  %s", continuation, line)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// the values of "--form" are double-quoted, "--form-string" takes the value as is
+func (g *CurlGenerator) formPart(req *client.HttpRequest, part client.HttpFormPart) string {
+	if len(part.File) > 0 {
+		arg := part.Name + "=@" + quoteFormValue(req.ResolvePath(part.File))
+		if len(part.Filename) > 0 {
+			arg += ";filename=" + quoteFormValue(part.Filename)
+		}
+		if len(part.ContentType) > 0 {
+			arg += ";type=" + part.ContentType
+		}
+		return "--form " + g.quote(arg)
+	}
+	if len(part.ContentType) > 0 {
+		return "--form " + g.quote(part.Name + "=" + quoteFormValue(part.Value) + ";type=" + part.ContentType)
+	}
+	return "--form-string " + g.quote(part.Name + "=" + part.Value)
+}
+
+func quoteFormValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// the unresolved expressions of the path are not escaped
+func buildUrlWithPlaceholders(req *client.HttpRequest) string {
+	expressions := sieve.STEP_VAR_EXPRESSION.FindAllString(req.Path, -1)
+	if len(req.Url) > 0 || len(expressions) == 0 {
+		return client.BuildUrl(req)
+	}
+	clone := *req
+	i := 0
+	clone.Path = sieve.STEP_VAR_EXPRESSION.ReplaceAllStringFunc(req.Path, func(exp string) string {
+		i++
+		return fmt.Sprintf("__placeholder_%d__", i - 1)
+	})
+	url := client.BuildUrl(&clone)
+	for i, exp := range expressions {
+		url = strings.Replace(url, fmt.Sprintf("__placeholder_%d__", i), exp, 1)
+	}
+	return url
+}
+
+func (g *CurlGenerator) quote(text string) string {
+	switch(g.style) {
+	case CURL_STYLE_POWERSHELL:
+		return quotePowerShell(text)
+	case CURL_STYLE_CMD:
+		return quoteCmd(text)
+	}
+	return quotePosix(text)
+}
+
+func quotePosix(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
+
+func quotePowerShell(text string) string {
+	// the typographic single quotes are the quotes of PowerShell too
+	var b strings.Builder
+	b.WriteRune('\'')
+	for _, c := range text {
+		switch(c) {
+		case '\'', '‘', '’', '‚', '‛':
+			b.WriteRune(c)
+		}
+		b.WriteRune(c)
+	}
+	b.WriteRune('\'')
+	return b.String()
+}
+
+func quoteCmd(text string) string {
+	// the quoting of the arguments for the C runtime of curl.exe
+	var arg strings.Builder
+	backslashes := 0
+	for _, c := range text {
+		switch(c) {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			arg.WriteString(strings.Repeat(`\`, backslashes * 2 + 1))
+		default:
+			arg.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		arg.WriteRune(c)
+		backslashes = 0
+	}
+	arg.WriteString(strings.Repeat(`\`, backslashes * 2))
+
+	// cmd.exe does not see the quotes as quotes, every special character is escaped
+	var b strings.Builder
+	b.WriteString(`^"`)
+	for _, c := range arg.String() {
+		switch(c) {
+		case '^', '&', '|', '<', '>', '(', ')', '%', '!', '"':
+			b.WriteRune('^')
+			b.WriteRune(c)
+		case '\r':
+		case '\n':
+			b.WriteString("^\n\n")
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteString(`^"`)
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package bootstrap
+
+import(
+	"bytes"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestCurlGenerator_generateCommand(t *testing.T) {
+	req := &client.HttpRequest{
+		Method: "POST",
+		Path: "/pets",
+		Headers: []client.HttpHeader{
+			{ Name: "Content-Type", Value: "application/json" },
+			{ Name: "X-Quote", Value: "it's" },
+		},
+		Body: `{ "name": "Kitty's 100%" }`,
+		Timeout: utils.RefOfString("1500ms"),
+	}
+
+	t.Run("POSIX style", func(t *testing.T) {
+		g, _ := newCurlGenerator("http://localhost:17779", "")
+		var w bytes.Buffer
+		assert.Nil(t, g.generateCommand(&w, req))
+		assert.Equal(t, "curl \\\n" +
+			"  --request 'POST' \\\n" +
+			"  --url 'http://localhost:17779/pets' \\\n" +
+			"  --header 'Content-Type: application/json' \\\n" +
+			"  --header 'X-Quote: it'\\''s' \\\n" +
+			"  --max-time 1.5 \\\n" +
+			"  --data-raw '{ \"name\": \"Kitty'\\''s 100%\" }'\n", w.String())
+	})
+
+	t.Run("GET without body", func(t *testing.T) {
+		g, _ := newCurlGenerator("", CURL_STYLE_POSIX)
+		var w bytes.Buffer
+		assert.Nil(t, g.generateCommand(&w, &client.HttpRequest{ Method: "GET", Url: "http://localhost/health" }))
+		assert.Equal(t, "curl \\\n  --url 'http://localhost/health'\n", w.String())
+		w.Reset()
+		assert.Nil(t, g.generateCommand(&w, &client.HttpRequest{ Method: "HEAD", Url: "http://localhost/health" }))
+		assert.Equal(t, "curl \\\n  --head \\\n  --url 'http://localhost/health'\n", w.String())
+	})
+
+	t.Run("Unresolved expressions are left as placeholders", func(t *testing.T) {
+		g, _ := newCurlGenerator("http://localhost:17779", "")
+		var w bytes.Buffer
+		assert.Nil(t, g.generateCommand(&w, &client.HttpRequest{ Method: "GET", Path: "/users/${{ case[login].Body[id] }}/a b" }))
+		assert.Equal(t, "curl \\\n  --url 'http://localhost:17779/users/${{ case[login].Body[id] }}/a%20b'\n", w.String())
+		w.Reset()
+		assert.Nil(t, g.generateCommand(&w, &client.HttpRequest{ Method: "GET", Path: "/pets", Query: []client.HttpQueryParam{
+			{ Name: "owner", Value: "${{ case[login].Body[id] }}" },
+			{ Name: "tag", Value: "it's" },
+		} }))
+		assert.Equal(t, "curl \\\n  --url 'http://localhost:17779/pets?owner=${{ case[login].Body[id] }}&tag=it%27s'\n", w.String())
+	})
+
+	t.Run("Body files & forms", func(t *testing.T) {
+		g, _ := newCurlGenerator("", "")
+		var w bytes.Buffer
+		file := &client.HttpRequest{ Method: "PUT", Url: "http://localhost/pets/1", BodyFile: "pet.json" }
+		file.SetBaseDir("/opt/tests")
+		assert.Nil(t, g.generateCommand(&w, file))
+		assert.Equal(t, "curl \\\n  --request 'PUT' \\\n  --url 'http://localhost/pets/1' \\\n  --data-binary '@/opt/tests/pet.json'\n", w.String())
+
+		w.Reset()
+		form := &client.HttpRequest{ Method: "POST", Url: "http://localhost/login", Form: []client.HttpFormField{
+			{ Name: "user name", Value: "it's me" },
+		} }
+		assert.Nil(t, g.generateCommand(&w, form))
+		assert.Equal(t, "curl \\\n  --request 'POST' \\\n  --url 'http://localhost/login' \\\n  --data-urlencode 'user+name=it'\\''s me'\n", w.String())
+
+		w.Reset()
+		parts := &client.HttpRequest{ Method: "POST", Url: "http://localhost/pets", Multipart: []client.HttpFormPart{
+			{ Name: "name", Value: "@Kitty;type=x" },
+			{ Name: "meta", Value: `{"a":"b"}`, ContentType: "application/json" },
+			{ Name: "photo", File: "/tmp/cat.png", Filename: "cat.png", ContentType: "image/png" },
+		} }
+		assert.Nil(t, g.generateCommand(&w, parts))
+		assert.Equal(t, "curl \\\n  --request 'POST' \\\n  --url 'http://localhost/pets' \\\n" +
+			"  --form-string 'name=@Kitty;type=x' \\\n" +
+			"  --form 'meta=\"{\\\"a\\\":\\\"b\\\"}\";type=application/json' \\\n" +
+			"  --form 'photo=@\"/tmp/cat.png\";filename=\"cat.png\";type=image/png'\n", w.String())
+	})
+
+	t.Run("PowerShell style", func(t *testing.T) {
+		g, _ := newCurlGenerator("http://localhost:17779", "PowerShell")
+		assert.Equal(t, `'it''s "quoted" $HOME'`, g.quote(`it's "quoted" $HOME`))
+		var w bytes.Buffer
+		assert.Nil(t, g.generateCommand(&w, &client.HttpRequest{ Method: "DELETE", Path: "/pets/1" }))
+		assert.Equal(t, "curl.exe `\n  --request 'DELETE' `\n  --url 'http://localhost:17779/pets/1'\n", w.String())
+	})
+
+	t.Run("cmd style", func(t *testing.T) {
+		g, _ := newCurlGenerator("", CURL_STYLE_CMD)
+		assert.Equal(t, `^"{\^"path\^":\^"C:\dir\\\^"} ^& 100^%^"`, g.quote(`{"path":"C:\dir\"} & 100%`))
+		assert.Equal(t, `^"a\b\\^"`, g.quote(`a\b\`))
+	})
+
+	t.Run("Invalid style & timeout", func(t *testing.T) {
+		_, err := newCurlGenerator("", "fish")
+		assert.NotNil(t, err)
+		g, _ := newCurlGenerator("", "")
+		var w bytes.Buffer
+		err = g.generateCommand(&w, &client.HttpRequest{ Url: "http://localhost", Timeout: utils.RefOfString("soon") })
+		assert.Equal(t, "Invalid timeout [soon] of the request", err.Error())
+	})
+}
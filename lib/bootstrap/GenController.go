@@ -1,19 +1,35 @@
 package bootstrap
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
 	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/har"
+	"github.com/opwire/opwire-testa/lib/openapi"
+	"github.com/opwire/opwire-testa/lib/postman"
 	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/sieve"
+	"github.com/opwire/opwire-testa/lib/storage"
 	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type GenControllerOptions interface {
 	script.Source
+	GetVersion() string
+	GetPDP() string
+	GetVariables() map[string]string
 	GetNoColor() bool
+	GetSnapshotProfile() *engine.GenerationProfile
 }
 
 type GenController struct {
@@ -23,11 +39,22 @@ type GenController struct {
 	tagManager *tag.Manager
 	outputPrinter *format.OutputPrinter
 	outWriter io.Writer
+	pdp string
+	variables map[string]string
+	version string
+	snapshotProfile *engine.GenerationProfile
 }
 
 func NewGenController(opts GenControllerOptions) (ref *GenController, err error) {
 	ref = &GenController{}
 
+	if opts != nil {
+		ref.pdp = opts.GetPDP()
+		ref.variables = opts.GetVariables()
+		ref.version = opts.GetVersion()
+		ref.snapshotProfile = opts.GetSnapshotProfile()
+	}
+
 	// testing temporary storage
 	ref.scriptSource, err = script.NewSource(opts)
 	if err != nil {
@@ -61,7 +88,14 @@ func NewGenController(opts GenControllerOptions) (ref *GenController, err error)
 	return ref, err
 }
 
-type GenArguments interface {}
+type GenArguments interface {
+	GetStyle() string
+	GetAll() bool
+	GetResolve() bool
+	GetOutput() string
+	GetScript() bool
+	GetResults() string
+}
 
 func (r *GenController) GetOutWriter() io.Writer {
 	if r.outWriter == nil {
@@ -74,25 +108,147 @@ func (r *GenController) SetOutWriter(writer io.Writer) {
 	r.outWriter = writer
 }
 
+// generates the curl commands of the selected testcases (in order), the expressions are resolved from the results of a run or left as they are
 func (r *GenController) Execute(args GenArguments) error {
+	generator, err := newCurlGenerator(r.pdp, args.GetStyle())
+	if err != nil {
+		return NewExitError(EXIT_CODE_INVALID, err)
+	}
+	if args.GetScript() && generator.style != CURL_STYLE_POSIX {
+		return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("The script can only be generated in the [%s] style", CURL_STYLE_POSIX))
+	}
+
+	// the commands are written to the standard output, the messages to the standard error
+	printer := r.outputPrinter
+	if len(args.GetOutput()) == 0 {
+		printer = r.outputPrinter.Clone()
+		printer.SetWriter(os.Stderr)
+	}
+
 	// display environment of command
-	r.outputPrinter.Println()
-	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
-	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+	printer.Println()
+	printer.Println(printer.Heading("Context"))
+	printScriptSourceArgs(printer, r.scriptSource, r.scriptSelector, r.tagManager)
 
 	// display prerequisites
-	r.outputPrinter.Println()
-	r.outputPrinter.Println(r.outputPrinter.Heading("Loading"))
+	printer.Println()
+	printer.Println(printer.Heading("Loading"))
 
-	// Load testing script files from "test-dirs"
-	descriptors := r.scriptLoader.Load()
+	testcases := r.loadTestCases(printer)
 
-	// filter invalid descriptors and display errors
-	descriptors, rejected := filterInvalidDescriptors(descriptors)
-	for _, d := range rejected {
-		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
-		r.outputPrinter.Println(r.outputPrinter.Section(d.Error.Error()))
+	// raise an error if testcase not found or more than one found
+	if len(testcases) == 0 {
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("There is no testcase satisfied criteria"))
+	}
+	if len(testcases) > 1 && !args.GetAll() {
+		testinfo := make([]string, len(testcases))
+		for i, testcase := range testcases {
+			testinfo[i] = testcase.Title
+			if len(testcase.Tags) > 0 {
+				testinfo[i] += " (tags: " + strings.Join(testcase.Tags, ", ") + ")"
+			}
+		}
+		printer.Println(printer.ContextInfo("Error", "There are more than one testcases satisfied criteria", testinfo...))
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("There are more than one testcases satisfied criteria, use --all to generate all of them"))
+	}
+
+	printer.Println()
+	printer.Println(printer.Heading("Generating"))
+
+	requests, err := r.resolveRequests(printer, testcases, args.GetResolve() || len(args.GetResults()) > 0, args.GetResults())
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	if args.GetScript() {
+		generator.generateScriptHeader(&content)
+	}
+	for i, testcase := range testcases {
+		if len(testcases) > 1 || args.GetScript() {
+			if i > 0 || args.GetScript() {
+				content.WriteString("\n")
+			}
+			generator.generateComment(&content, testcase.Title)
+		}
+		if err := generator.generateCommand(&content, requests[i]); err != nil {
+			return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("[%s] %s", testcase.Title, err))
+		}
+	}
+
+	if len(args.GetOutput()) == 0 {
+		_, err = r.GetOutWriter().Write(content.Bytes())
+		printer.Println()
+		return err
+	}
+	fs := storage.GetFs()
+	file, err := fs.Create(args.GetOutput())
+	if err != nil {
+		return fmt.Errorf("Cannot create the file [%s], error: %s", args.GetOutput(), err)
 	}
+	defer file.Close()
+	if _, err := file.Write(content.Bytes()); err != nil {
+		return err
+	}
+	if args.GetScript() {
+		if err := fs.Chmod(args.GetOutput(), 0755); err != nil {
+			return fmt.Errorf("Cannot make the script [%s] executable, error: %s", args.GetOutput(), err)
+		}
+	}
+	printer.Println(printer.Success(fmt.Sprintf("%s (%d testcase(s))", args.GetOutput(), len(testcases))))
+	printer.Println()
+	return nil
+}
+
+// evaluates the expressions of the requests, the references to the captured results are read from the results of a run (never invoked)
+func (r *GenController) resolveRequests(printer *format.OutputPrinter, testcases []*engine.TestCase, resolve bool, resultsPath string) ([]*client.HttpRequest, error) {
+	requests := make([]*client.HttpRequest, len(testcases))
+	if !resolve {
+		for i, testcase := range testcases {
+			requests[i] = testcase.Request
+		}
+		return requests, nil
+	}
+
+	var store *sieve.ResultStore
+	if len(resultsPath) > 0 {
+		var err error
+		store, err = sieve.LoadResultStore(resultsPath)
+		if err != nil {
+			return nil, NewExitError(EXIT_CODE_INVALID, err)
+		}
+	}
+
+	// the results are captured by the test suites separately
+	caches := make(map[string]*sieve.RestCache)
+	for i, testcase := range testcases {
+		cache, ok := caches[testcase.GetSourcePath()]
+		if !ok {
+			var err error
+			cache, err = sieve.NewRestCache()
+			if err != nil {
+				return nil, err
+			}
+			cache.SetVariables(r.variables)
+			if store != nil {
+				if err := store.Restore(testcase.GetSourcePath(), cache); err != nil {
+					return nil, NewExitError(EXIT_CODE_INVALID, err)
+				}
+			}
+			caches[testcase.GetSourcePath()] = cache
+		}
+		req, err := cache.Apply(testcase.Request)
+		if err != nil {
+			printer.Println(printer.WarnMsg(fmt.Sprintf("[%s] the unresolved expressions are left as placeholders", testcase.Title)))
+		}
+		requests[i] = req
+	}
+	return requests, nil
+}
+
+func (r *GenController) loadTestCases(printer *format.OutputPrinter) []*engine.TestCase {
+	// Load testing script files from "test-dirs"
+	descriptors := r.scriptLoader.Load()
 
 	// filter testing script files by "inclusive-files"
 	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
@@ -100,53 +256,279 @@ func (r *GenController) Execute(args GenArguments) error {
 	// filter testing script files by "exclusive-files"
 	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
 
+	// filter invalid descriptors and display errors
+	descriptors, rejected := filterInvalidDescriptors(descriptors)
+	for _, d := range rejected {
+		printer.Println(printer.TestSuiteTitle(d.Locator.RelativePath))
+		printer.Println(printer.Section(d.Error.Error()))
+	}
+
 	// filter target testcase by "test-name" title/name
 	testcases := r.scriptSelector.GetTestCases(descriptors)
 
 	// filter testcases by conditional tags
 	testcases, _ = filterTestCasesByTags(r.tagManager, testcases)
+	return testcases
+}
 
-	// running & result
-	r.outputPrinter.Println()
-	r.outputPrinter.Println(r.outputPrinter.Heading("Running"))
+type GenPostmanArguments interface {
+	GetName() string
+	GetOutput() string
+}
 
-	// raise an error if testcase not found or more than one found
+// exports the selected testcases to a Postman collection, a folder per test suite file
+func (r *GenController) ExportPostman(args GenPostmanArguments) error {
+	// the collection is written to the standard output, the messages to the standard error
+	printer := r.outputPrinter
+	if len(args.GetOutput()) == 0 {
+		printer = r.outputPrinter.Clone()
+		printer.SetWriter(os.Stderr)
+	}
+
+	printer.Println()
+	printer.Println(printer.Heading("Context"))
+	printScriptSourceArgs(printer, r.scriptSource, r.scriptSelector, r.tagManager)
+
+	printer.Println()
+	printer.Println(printer.Heading("Loading"))
+
+	testcases := r.loadTestCases(printer)
 	if len(testcases) == 0 {
-		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Error", "There is no testcase satisfied criteria"))
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("There is no testcase satisfied criteria"))
 	}
-	if len(testcases) > 1 {
-		testinfo := make([]string, len(testcases))
-		for i, testcase := range testcases {
-			testinfo[i] = testcase.Title
-			if len(testcase.Tags) > 0 {
-				testinfo[i] += " (tags: " + strings.Join(testcase.Tags, ", ") + ")"
+
+	groups := make(map[string][]*engine.TestCase)
+	for _, testcase := range testcases {
+		group := r.relativeToTestDirs(testcase.GetSourcePath())
+		group = strings.TrimSuffix(group, filepath.Ext(group))
+		groups[group] = append(groups[group], testcase)
+	}
+
+	name := args.GetName()
+	if len(name) == 0 {
+		name = "opwire-testa"
+	}
+	builder, err := engine.NewSpecBuilder()
+	if err != nil {
+		return err
+	}
+	collection := builder.BuildPostmanCollection(name, r.pdp, groups)
+	content, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Cannot marshal the Postman collection, error: %s", err)
+	}
+	content = append(content, '\n')
+
+	if len(args.GetOutput()) == 0 {
+		_, err = r.GetOutWriter().Write(content)
+		return err
+	}
+	file, err := storage.GetFs().Create(args.GetOutput())
+	if err != nil {
+		return fmt.Errorf("Cannot create the Postman collection [%s], error: %s", args.GetOutput(), err)
+	}
+	defer file.Close()
+	if _, err := file.Write(content); err != nil {
+		return err
+	}
+
+	printer.Println()
+	printer.Println(printer.Heading("Exporting"))
+	printer.Println(printer.Success(fmt.Sprintf("%s (%d testcase(s))", args.GetOutput(), len(testcases))))
+	printer.Println()
+	return nil
+}
+
+func (r *GenController) relativeToTestDirs(filePath string) string {
+	for _, testDir := range r.scriptSource.GetTestDirs() {
+		if abs, err := filepath.Abs(testDir); err == nil {
+			if rel, err := filepath.Rel(abs, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
 			}
 		}
-		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Error", "There are more than one testcases satisfied criteria", testinfo...))
 	}
+	if rel, err := utils.DetectRelativePath(filePath); err == nil {
+		return rel
+	}
+	return filePath
+}
 
-	// generate curl statement from testcase's request
-	if len(testcases) == 1 {
-		testcase := testcases[0]
-		request := testcase.Request
+type GenTestSuiteArguments interface {
+	GetFromOpenAPI() string
+	GetFromHAR() string
+	GetFromPostman() string
+	GetGroupBy() string
+	GetHostPattern() string
+	GetPathPattern() string
+	GetDropHeaders() []string
+	GetOutputDir() string
+	GetForce() bool
+}
 
-		generator := new(CurlGenerator)
-		generator.generateCommand(r.GetOutWriter(), request)
+func (r *GenController) GenerateTestSuites(args GenTestSuiteArguments) error {
+	outputDir := args.GetOutputDir()
+	if len(outputDir) == 0 {
+		if testDirs := r.scriptSource.GetTestDirs(); len(testDirs) > 0 {
+			outputDir = testDirs[0]
+		}
+	}
+	if len(outputDir) == 0 {
+		return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("The output directory must be specified by --output-dir or the test directories"))
+	}
+
+	// display environment of command
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
+	if len(args.GetFromHAR()) > 0 {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("HAR file", args.GetFromHAR()))
+	} else if len(args.GetFromPostman()) > 0 {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Postman collection", args.GetFromPostman()))
+	} else {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("OpenAPI document", args.GetFromOpenAPI()))
+	}
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Output directory", outputDir))
+
+	// load the source & build the test suites
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Generating"))
+
+	builder, err := engine.NewSpecBuilder()
+	if err != nil {
+		return err
+	}
+	builder.Version = r.version
+
+	sources := 0
+	for _, source := range []string{ args.GetFromOpenAPI(), args.GetFromHAR(), args.GetFromPostman() } {
+		if len(source) > 0 {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("One of --from-openapi, --from-har and --from-postman must be specified"))
+	}
+
+	var suites map[string]*engine.TestSuite
+	switch {
+	case len(args.GetFromHAR()) > 0:
+		suites, err = r.buildTestSuitesFromHAR(builder, args)
+	case len(args.GetFromPostman()) > 0:
+		suites, err = r.buildTestSuitesFromPostman(builder, args)
+	default:
+		suites, err = r.buildTestSuitesFromOpenAPI(builder, args)
+	}
+	if err != nil {
+		return NewExitError(EXIT_CODE_INVALID, err)
+	}
+
+	fs := storage.GetFs()
+	if err := fs.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("Cannot create the output directory [%s], error: %s", outputDir, err)
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	written := make(map[string]bool)
+	for _, name := range names {
+		fileName := toFileName(name)
+		for i := 2; written[fileName]; i++ {
+			fileName = fmt.Sprintf("%s-%d", toFileName(name), i)
+		}
+		written[fileName] = true
+		filePath := filepath.Join(outputDir, fileName + ".yml")
+
+		if _, err := fs.Stat(filePath); err == nil && !args.GetForce() {
+			r.outputPrinter.Println(r.outputPrinter.Skipped(filePath + " (already exists)"))
+			continue
+		}
+		if err := r.writeTestSuite(builder, filePath, suites[name]); err != nil {
+			return err
+		}
+
+		// the generated file must be loaded as the other test suites
+		descriptor := r.scriptLoader.LoadFile(&script.Locator{ AbsolutePath: filePath, RelativePath: filePath })
+		if descriptor.Error != nil {
+			r.outputPrinter.Println(r.outputPrinter.Failure(filePath))
+			r.outputPrinter.Println(r.outputPrinter.Section(descriptor.Error.Error()))
+			continue
+		}
+		r.outputPrinter.Println(r.outputPrinter.Success(fmt.Sprintf("%s (%d testcase(s))", filePath, len(suites[name].TestCases))))
 	}
 
 	r.outputPrinter.Println()
 	return nil
 }
 
-type CurlGenerator struct {}
+func (r *GenController) buildTestSuitesFromOpenAPI(builder *engine.SpecBuilder, args GenTestSuiteArguments) (map[string]*engine.TestSuite, error) {
+	doc, err := openapi.LoadDocument(args.GetFromOpenAPI())
+	if err != nil {
+		return nil, err
+	}
+	return builder.BuildTestSuites(doc, args.GetGroupBy())
+}
 
-func (g *CurlGenerator) generateCommand(w io.Writer, req *client.HttpRequest) error {
-	fmt.Fprintf(w, "curl \\\n")
-	fmt.Fprintf(w, "  --request %s \\\n", req.Method)
-	fmt.Fprintf(w, "  --url \"%s\" \\\n", client.BuildUrl(req))
-	for _, header := range req.Headers {
-		fmt.Fprintf(w, "  --header '%s: %s' \\\n", header.Name, header.Value)
+// the entries of a HAR file are generated into a single test suite, named after the file
+func (r *GenController) buildTestSuitesFromHAR(builder *engine.SpecBuilder, args GenTestSuiteArguments) (map[string]*engine.TestSuite, error) {
+	if err := builder.ApplyProfile(r.snapshotProfile); err != nil {
+		return nil, err
 	}
-	fmt.Fprintf(w, "  --data='%s'\n", req.Body)
-	return nil
+	archive, err := har.LoadArchive(args.GetFromHAR())
+	if err != nil {
+		return nil, err
+	}
+	suite, err := builder.BuildTestSuiteFromHAR(archive, &engine.HARFilter{
+		HostPattern: args.GetHostPattern(),
+		PathPattern: args.GetPathPattern(),
+		DroppedHeaders: args.GetDropHeaders(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(suite.TestCases) == 0 {
+		return nil, fmt.Errorf("There is no entry of the HAR file satisfied criteria")
+	}
+	name := strings.TrimSuffix(filepath.Base(args.GetFromHAR()), filepath.Ext(args.GetFromHAR()))
+	return map[string]*engine.TestSuite{ name: suite }, nil
+}
+
+// the requests of a Postman collection are generated into a test suite per folder
+func (r *GenController) buildTestSuitesFromPostman(builder *engine.SpecBuilder, args GenTestSuiteArguments) (map[string]*engine.TestSuite, error) {
+	if err := builder.ApplyProfile(r.snapshotProfile); err != nil {
+		return nil, err
+	}
+	collection, err := postman.LoadCollection(args.GetFromPostman())
+	if err != nil {
+		return nil, err
+	}
+	suites, warnings := builder.BuildTestSuitesFromPostman(collection)
+	for _, warning := range warnings {
+		r.outputPrinter.Println(r.outputPrinter.WarnMsg(warning))
+	}
+	if len(suites) == 0 {
+		return nil, fmt.Errorf("There is no request in the Postman collection")
+	}
+	return suites, nil
+}
+
+func (r *GenController) writeTestSuite(builder *engine.SpecBuilder, filePath string, suite *engine.TestSuite) error {
+	file, err := storage.GetFs().Create(filePath)
+	if err != nil {
+		return fmt.Errorf("Cannot create the test suite file [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	return builder.WriteTestSuite(file, suite)
+}
+
+var fileNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func toFileName(name string) string {
+	fileName := strings.Trim(fileNameRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(fileName) == 0 {
+		return "root"
+	}
+	return fileName
 }
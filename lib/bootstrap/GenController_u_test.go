@@ -0,0 +1,161 @@
+package bootstrap
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+func TestGenController_resolveRequests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-gen")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	suite := &engine.TestSuite{ TestCases: []*engine.TestCase{
+		{
+			Title: "create user",
+			Request: &client.HttpRequest{ Method: "POST", Path: "/v1/users" },
+			Capture: &engine.SectionCapture{ StoreID: "create-user" },
+		},
+		{
+			Title: "get user",
+			Request: &client.HttpRequest{
+				Method: "GET",
+				Path: "/v1/users/${{ case[create-user].Body[id] }}",
+				Headers: []client.HttpHeader{ { Name: "Authorization", Value: "Bearer ${{ env.TOKEN }}" } },
+			},
+		},
+	} }
+	suite.SetSourcePath(filepath.Join(dir, "users.yml"))
+
+	cache, _ := sieve.NewRestCache()
+	_, err = cache.Store("create-user", &client.HttpResponse{ StatusCode: 201, Body: []byte(`{ "id": "u1" }`) })
+	assert.Nil(t, err)
+	store := sieve.NewResultStore()
+	store.Collect(filepath.Join(dir, "users.yml"), cache)
+	resultsPath := filepath.Join(dir, "results.json")
+	assert.Nil(t, store.Save(resultsPath))
+
+	r := &GenController{ variables: map[string]string{ "TOKEN": "secret" } }
+	printer, _ := format.NewOutputPrinter(nil)
+	printer.SetWriter(ioutil.Discard)
+
+	// the references are read from the results, no request is sent
+	requests, err := r.resolveRequests(printer, suite.TestCases, true, resultsPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/users/u1", requests[1].Path)
+	assert.Equal(t, "Bearer secret", requests[1].Headers[0].Value)
+
+	requests, err = r.resolveRequests(printer, suite.TestCases, true, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/users/${{ case[create-user].Body[id] }}", requests[1].Path)
+	assert.Equal(t, "Bearer secret", requests[1].Headers[0].Value)
+
+	_, err = r.resolveRequests(printer, suite.TestCases, true, filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
+
+type genOptionsStub struct {
+	runOptionsStub
+}
+
+func (o *genOptionsStub) GetVersion() string { return "" }
+
+type genTestSuiteArgumentsStub struct {
+	fromOpenAPI string
+	outputDir string
+	force bool
+}
+
+func (a *genTestSuiteArgumentsStub) GetFromOpenAPI() string { return a.fromOpenAPI }
+func (a *genTestSuiteArgumentsStub) GetFromHAR() string { return "" }
+func (a *genTestSuiteArgumentsStub) GetFromPostman() string { return "" }
+func (a *genTestSuiteArgumentsStub) GetGroupBy() string { return "" }
+func (a *genTestSuiteArgumentsStub) GetHostPattern() string { return "" }
+func (a *genTestSuiteArgumentsStub) GetPathPattern() string { return "" }
+func (a *genTestSuiteArgumentsStub) GetDropHeaders() []string { return nil }
+func (a *genTestSuiteArgumentsStub) GetOutputDir() string { return a.outputDir }
+func (a *genTestSuiteArgumentsStub) GetForce() bool { return a.force }
+
+func TestGenController_GenerateTestSuites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-gen")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	specPath := filepath.Join(dir, "spec.yaml")
+	assert.Nil(t, ioutil.WriteFile(specPath, []byte(`
+openapi: 3.0.1
+info: { title: Petstore, version: 1.0.0 }
+paths:
+  /pets:
+    get:
+      tags: [ pets ]
+      summary: List the pets
+      parameters:
+        - { name: limit, in: query, required: true, schema: { type: integer, example: 5 } }
+      responses:
+        '200':
+          description: the pets
+`), 0644))
+
+	newGenController := func(testDirs ...string) *GenController {
+		r, err := NewGenController(&genOptionsStub{ runOptionsStub{ testDirs: testDirs } })
+		assert.Nil(t, err)
+		r.outputPrinter.SetWriter(ioutil.Discard)
+		return r
+	}
+	outputDir := filepath.Join(dir, "generated")
+	filePath := filepath.Join(outputDir, "pets.yml")
+
+	t.Run("The test suites are written into the output directory", func(t *testing.T) {
+		r := newGenController()
+		assert.Nil(t, r.GenerateTestSuites(&genTestSuiteArgumentsStub{ fromOpenAPI: specPath, outputDir: outputDir }))
+		content, err := ioutil.ReadFile(filePath)
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "testcases:\n- title: GET pets - List the pets\n"))
+		assert.Contains(t, string(content), "    path: /pets\n    query:\n    - name: limit\n      value: \"5\"\n")
+		assert.NotContains(t, string(content), "capture")
+
+		// the generated test suite can be loaded
+		descriptor := r.scriptLoader.LoadFile(&script.Locator{ AbsolutePath: filePath, RelativePath: filePath })
+		assert.Nil(t, descriptor.Error)
+		assert.Equal(t, 1, len(descriptor.TestSuite.TestCases))
+	})
+
+	t.Run("The existing files are kept without --force", func(t *testing.T) {
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte("testcases: []\n"), 0644))
+		r := newGenController()
+		assert.Nil(t, r.GenerateTestSuites(&genTestSuiteArgumentsStub{ fromOpenAPI: specPath, outputDir: outputDir }))
+		content, _ := ioutil.ReadFile(filePath)
+		assert.Equal(t, "testcases: []\n", string(content))
+
+		assert.Nil(t, r.GenerateTestSuites(&genTestSuiteArgumentsStub{ fromOpenAPI: specPath, outputDir: outputDir, force: true }))
+		content, _ = ioutil.ReadFile(filePath)
+		assert.Contains(t, string(content), "GET pets - List the pets")
+	})
+
+	t.Run("The output directory falls back to the first test directory", func(t *testing.T) {
+		r := newGenController(filepath.Join(dir, "tests"))
+		assert.Nil(t, r.GenerateTestSuites(&genTestSuiteArgumentsStub{ fromOpenAPI: specPath }))
+		_, err := os.Stat(filepath.Join(dir, "tests", "pets.yml"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("The output directory must be resolvable", func(t *testing.T) {
+		r := newGenController()
+		r.scriptSource = &script.SourceBuffer{ TestDirs: []string{} }
+		err := r.GenerateTestSuites(&genTestSuiteArgumentsStub{ fromOpenAPI: specPath })
+		assert.NotNil(t, err)
+		exitErr, ok := err.(*ExitError)
+		assert.True(t, ok)
+		assert.Equal(t, EXIT_CODE_INVALID, exitErr.ExitCode())
+	})
+}
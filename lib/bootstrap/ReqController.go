@@ -7,30 +7,43 @@ import (
 	"strconv"
 	"strings"
 	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/curl"
 	"github.com/opwire/opwire-testa/lib/engine"
 	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type ReqArguments interface {
+	GetCommand() []string
 	GetMethod() string
 	GetUrl() string
 	GetHeader() []string
 	GetBody() string
 	GetFormat() string
+	GetAppendTo() string
+	GetTitle() string
+	GetTags() []string
 }
 
 type ReqControllerOptions interface {
 	GetVersion() string
+	GetPDP() string
+	GetTimeout() string
+	GetTransport() *client.HttpTransportOptions
 	GetNoColor() bool
+	GetSnapshotProfile() *engine.GenerationProfile
 }
 
 type ReqController struct {
 	httpInvoker client.HttpInvoker
+	httpInvokerOptions *client.HttpInvokerOptions
 	specBuilder *engine.SpecBuilder
+	scriptWriter *script.Writer
 	outputPrinter *format.OutputPrinter
 	outWriter io.Writer
 	errWriter io.Writer
+	pdp string
 }
 
 func NewReqController(opts ReqControllerOptions) (obj *ReqController, err error) {
@@ -38,10 +51,17 @@ func NewReqController(opts ReqControllerOptions) (obj *ReqController, err error)
 
 	// create a HTTP Invoker instance
 	httpInvokerOptions := &client.HttpInvokerOptions{}
+	if opts != nil {
+		obj.pdp = opts.GetPDP()
+		httpInvokerOptions.PDP = obj.pdp
+		httpInvokerOptions.Timeout = opts.GetTimeout()
+		httpInvokerOptions.Transport = opts.GetTransport()
+	}
 	obj.httpInvoker, err = client.NewHttpInvoker(httpInvokerOptions)
 	if err != nil {
-		return nil, err
+		return nil, NewExitError(EXIT_CODE_INVALID, err)
 	}
+	obj.httpInvokerOptions = httpInvokerOptions
 
 	// create a SpecBuilder instance
 	obj.specBuilder, err = engine.NewSpecBuilder()
@@ -50,6 +70,15 @@ func NewReqController(opts ReqControllerOptions) (obj *ReqController, err error)
 	}
 	if opts != nil {
 		obj.specBuilder.Version = opts.GetVersion()
+		if err = obj.specBuilder.ApplyProfile(opts.GetSnapshotProfile()); err != nil {
+			return nil, NewExitError(EXIT_CODE_INVALID, err)
+		}
+	}
+
+	// create a Script Writer instance
+	obj.scriptWriter, err = script.NewWriter()
+	if err != nil {
+		return nil, err
 	}
 
 	// create a OutputPrinter instance
@@ -88,21 +117,47 @@ func (z *ReqController) Execute(args ReqArguments) error {
 
 	generationPrinter := &GenerationPrinter{
 		generator: z.specBuilder,
+		scriptWriter: z.scriptWriter,
 		writer: z.GetOutWriter(),
+		appendTo: args.GetAppendTo(),
+		title: args.GetTitle(),
 	}
 	invocationPrinter := &InvocationPrinter{
 		writer: z.GetOutWriter(),
 	}
 
-	if args.GetFormat() == "testcase" {
-		_, err := z.httpInvoker.Do(transformReqArgs(args), generationPrinter)
+	if args.GetFormat() == "testcase" || len(args.GetAppendTo()) > 0 {
+		for _, item := range args.GetTags() {
+			for _, label := range utils.Split(item, ",") {
+				tag, err := utils.StandardizeTagLabel(label)
+				if err != nil {
+					return NewExitError(EXIT_CODE_INVALID, err)
+				}
+				generationPrinter.tags = append(generationPrinter.tags, tag)
+			}
+		}
+		// refuses the duplicated title before making the request
+		if len(generationPrinter.appendTo) > 0 && len(generationPrinter.title) > 0 {
+			if err := z.scriptWriter.AssertAppendable(generationPrinter.appendTo, generationPrinter.title); err != nil {
+				return NewExitError(EXIT_CODE_INVALID, err)
+			}
+		}
+		req, invoker, err := z.buildRequest(args)
+		if err != nil {
+			return err
+		}
+		res, err := invoker.Do(req)
 		if err != nil {
 			return z.displayError(err)
 		}
-		return nil
+		return generationPrinter.PostProcess(req, res)
 	}
 
-	res, err := z.httpInvoker.Do(transformReqArgs(args), invocationPrinter)
+	req, invoker, err := z.buildRequest(args)
+	if err != nil {
+		return err
+	}
+	res, err := invoker.Do(req, invocationPrinter)
 	if err != nil {
 		return z.displayError(err)
 	}
@@ -112,7 +167,11 @@ func (z *ReqController) Execute(args ReqArguments) error {
 
 type GenerationPrinter struct {
 	generator *engine.SpecBuilder
+	scriptWriter *script.Writer
 	writer io.Writer
+	appendTo string
+	title string
+	tags []string
 }
 
 func (r *GenerationPrinter) PostProcess(req *client.HttpRequest, res *client.HttpResponse) error {
@@ -122,7 +181,25 @@ func (r *GenerationPrinter) PostProcess(req *client.HttpRequest, res *client.Htt
 	if r.writer == nil {
 		panic(fmt.Errorf("GenerationPrinter.writer must not be nil"))
 	}
-	return r.generator.GenerateTestCase(r.writer, req, res)
+	testcase := r.generator.BuildTestCase(req, res)
+	if len(r.title) > 0 {
+		testcase.Title = r.title
+	} else if len(r.appendTo) > 0 {
+		testcase.Title = engine.GenerateTitle(req)
+	}
+	for _, tag := range r.tags {
+		if !utils.Contains(testcase.Tags, tag) {
+			testcase.Tags = append(testcase.Tags, tag)
+		}
+	}
+	if len(r.appendTo) == 0 {
+		return r.generator.WriteSnapshot(r.writer, testcase)
+	}
+	if err := r.scriptWriter.AppendTestCase(r.appendTo, testcase); err != nil {
+		return NewExitError(EXIT_CODE_INVALID, err)
+	}
+	fmt.Fprintf(r.writer, "* Testcase [%s] has been appended to [%s]\n", testcase.Title, r.appendTo)
+	return nil
 }
 
 type InvocationPrinter struct {
@@ -234,7 +311,61 @@ func (z *ReqController) displayResult(res *client.HttpResponse) *client.HttpResp
 	return res
 }
 
-func transformReqArgs(args ReqArguments) *client.HttpRequest {
+// the request of the curl command line (if any), the request flags take precedence
+func (z *ReqController) buildRequest(args ReqArguments) (*client.HttpRequest, client.HttpInvoker, error) {
+	req := transformReqArgs(args, z.pdp)
+	if len(args.GetCommand()) == 0 {
+		return req, z.httpInvoker, nil
+	}
+	cmd, err := curl.ParseArgs(args.GetCommand())
+	if err != nil {
+		return nil, nil, NewExitError(EXIT_CODE_INVALID, err)
+	}
+	for _, warning := range cmd.Warnings {
+		z.outputPrinter.Println(z.outputPrinter.WarnMsg(warning))
+	}
+	if len(args.GetMethod()) > 0 {
+		cmd.Request.Method = req.Method
+	}
+	if len(args.GetUrl()) > 0 {
+		cmd.Request.Url = req.Url
+	}
+	cmd.Request.Headers = append(cmd.Request.Headers, req.Headers...)
+	if len(args.GetBody()) > 0 {
+		cmd.Request.Body = req.Body
+	}
+	invoker, err := z.invokerOf(cmd)
+	if err != nil {
+		return nil, nil, NewExitError(EXIT_CODE_INVALID, err)
+	}
+	return cmd.Request, invoker, nil
+}
+
+// the "-k" & "-L" options of the curl command line change the transport settings, as curl the redirects are not followed without "-L"
+func (z *ReqController) invokerOf(cmd *curl.Command) (client.HttpInvoker, error) {
+	opts := *z.httpInvokerOptions
+	transport := client.HttpTransportOptions{}
+	if opts.Transport != nil {
+		transport = *opts.Transport
+	}
+	redirects := transport.Redirects
+	switch {
+	case cmd.FollowRedirects && (redirects == "" || redirects == client.REDIRECTS_NONE):
+		transport.Redirects = client.REDIRECTS_FOLLOW
+	case !cmd.FollowRedirects && redirects == "":
+		transport.Redirects = client.REDIRECTS_NONE
+	}
+	if !cmd.Insecure && transport.Redirects == redirects {
+		return z.httpInvoker, nil
+	}
+	if cmd.Insecure {
+		transport.InsecureSkipVerify = true
+	}
+	opts.Transport = &transport
+	return client.NewHttpInvoker(&opts)
+}
+
+func transformReqArgs(args ReqArguments, pdp string) *client.HttpRequest {
 	req := &client.HttpRequest{}
 
 	req.Method = args.GetMethod()
@@ -244,7 +375,10 @@ func transformReqArgs(args ReqArguments) *client.HttpRequest {
 
 	req.Url = args.GetUrl()
 	if len(req.Url) == 0 {
-		req.Url, _ = utils.UrlJoin(utils.DEFAULT_PDP, utils.DEFAULT_PATH)
+		if len(pdp) == 0 {
+			pdp = utils.DEFAULT_PDP
+		}
+		req.Url, _ = utils.UrlJoin(pdp, utils.DEFAULT_PATH)
 	}
 
 	req.Headers = make([]client.HttpHeader, 0)
@@ -0,0 +1,44 @@
+package bootstrap
+
+import(
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/curl"
+)
+
+func TestReqController_invokerOf_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		w.Write([]byte("moved"))
+	}))
+	defer server.Close()
+
+	z, err := NewReqController(nil)
+	assert.Nil(t, err)
+
+	t.Run("Redirects are not followed without -L", func(t *testing.T) {
+		cmd, err := curl.Parse("curl " + server.URL + "/old")
+		assert.Nil(t, err)
+		invoker, err := z.invokerOf(cmd)
+		assert.Nil(t, err)
+		res, err := invoker.Do(cmd.Request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+	})
+
+	t.Run("Redirects are followed with -L", func(t *testing.T) {
+		cmd, err := curl.Parse("curl -L " + server.URL + "/old")
+		assert.Nil(t, err)
+		invoker, err := z.invokerOf(cmd)
+		assert.Nil(t, err)
+		res, err := invoker.Do(cmd.Request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "moved", string(res.Body))
+	})
+}
@@ -1,22 +1,31 @@
 package bootstrap
 
 import (
-	"flag"
+	"bytes"
 	"fmt"
-	"io"
+	"os"
+	"sort"
+	"sync"
 	"testing"
 	"time"
-	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/client"
 	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/report"
 	"github.com/opwire/opwire-testa/lib/script"
 	"github.com/opwire/opwire-testa/lib/sieve"
 	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type RunControllerOptions interface {
 	script.Source
+	engine.SpecHandlerOptions
 	GetConfigPath() string
+	GetEnv() string
+	GetVariables() map[string]string
 	GetNoColor() bool
+	GetSnapshotProfile() *engine.GenerationProfile
 }
 
 type RunController struct {
@@ -26,6 +35,9 @@ type RunController struct {
 	tagManager *tag.Manager
 	specHandler *engine.SpecHandler
 	outputPrinter *format.OutputPrinter
+	env string
+	variables map[string]string
+	snapshotProfile *engine.GenerationProfile
 	counter struct{
 		Pending int
 		Skipped int
@@ -33,12 +45,23 @@ type RunController struct {
 		Failure int
 		Cracked int
 	}
+	reporters []report.Reporter
+	parallel int
+	limiter chan bool
+	snapshots *snapshotUpdater
+	mutex sync.Mutex
 	t *testing.T
 }
 
 func NewRunController(opts RunControllerOptions) (r *RunController, err error) {
 	r = &RunController{}
 
+	if opts != nil {
+		r.env = opts.GetEnv()
+		r.variables = opts.GetVariables()
+		r.snapshotProfile = opts.GetSnapshotProfile()
+	}
+
 	// testing temporary storage
 	r.scriptSource, err = script.NewSource(opts)
 	if err != nil {
@@ -64,9 +87,9 @@ func NewRunController(opts RunControllerOptions) (r *RunController, err error) {
 	}
 
 	// create a Spec Handler instance
-	r.specHandler, err = engine.NewSpecHandler(r.scriptSource)
+	r.specHandler, err = engine.NewSpecHandler(opts)
 	if err != nil {
-		return nil, err
+		return nil, NewExitError(EXIT_CODE_INVALID, err)
 	}
 
 	// create a OutputPrinter instance
@@ -86,16 +109,74 @@ func (r *RunController) GetOutputPrinter() *format.OutputPrinter {
 	return r.outputPrinter
 }
 
-type RunArguments interface {}
+type RunArguments interface {
+	GetOutputFormat() string
+	GetReportFormat() string
+	GetReportFile() string
+	GetFailOnPending() bool
+	GetFailOnSkipped() bool
+	GetParallel() int
+	GetUpdateSnapshots() bool
+	GetSaveResults() string
+}
 
 func (r *RunController) Execute(args RunArguments) error {
 	// start time
 	startTime := time.Now()
 
+	r.parallel = 1
+	r.limiter = nil
+	if args != nil && args.GetParallel() > 1 {
+		r.parallel = args.GetParallel()
+		// the suites & the testcases share the slots, at most "parallel" requests are in flight
+		r.limiter = make(chan bool, r.parallel)
+	}
+
+	r.snapshots = nil
+	if args != nil && args.GetUpdateSnapshots() {
+		updater, err := newSnapshotUpdater(r.snapshotProfile)
+		if err != nil {
+			return NewExitError(EXIT_CODE_INVALID, err)
+		}
+		r.snapshots = updater
+	}
+
+	// create the reporters
+	r.reporters = make([]report.Reporter, 0)
+	if args != nil && len(args.GetOutputFormat()) > 0 && args.GetOutputFormat() != OUTPUT_FORMAT_TEXT {
+		if args.GetOutputFormat() != report.REPORT_FORMAT_JSON && args.GetOutputFormat() != report.REPORT_FORMAT_JSONL {
+			return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("Unsupported output format [%s]", args.GetOutputFormat()))
+		}
+		reporter, err := report.NewReporter(args.GetOutputFormat(), "")
+		if err != nil {
+			return NewExitError(EXIT_CODE_INVALID, err)
+		}
+		r.reporters = append(r.reporters, reporter)
+		// the structured output replaces the human readable text
+		r.outputPrinter.SetWriter(utils.DevNull(0))
+	}
+	if args != nil && len(args.GetReportFormat()) == 0 && len(args.GetReportFile()) > 0 {
+		return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("The report file [%s] requires a report format (--report)", args.GetReportFile()))
+	}
+	if args != nil && len(args.GetReportFormat()) > 0 {
+		// the JSON reports are written to stdout without a report file, the output is stdout already
+		if len(args.GetReportFile()) == 0 && len(r.reporters) > 0 && args.GetReportFormat() != report.REPORT_FORMAT_JUNIT {
+			return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("The report [%s] requires a --report-file with the output format [%s]", args.GetReportFormat(), args.GetOutputFormat()))
+		}
+		reporter, err := report.NewReporter(args.GetReportFormat(), args.GetReportFile())
+		if err != nil {
+			return NewExitError(EXIT_CODE_INVALID, err)
+		}
+		r.reporters = append(r.reporters, reporter)
+	}
+
 	// begin environments
 	r.outputPrinter.Println()
 	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
 	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+	if len(r.env) > 0 {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Environment", r.env))
+	}
 
 	// begin prerequisites
 	r.outputPrinter.Println()
@@ -104,174 +185,488 @@ func (r *RunController) Execute(args RunArguments) error {
 	// load test specifications
 	descriptors := r.scriptLoader.Load()
 
+	// filter testing script files by "inclusive-files"
+	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
+
+	// filter testing script files by "exclusive-files"
+	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
+
 	// filter invalid descriptors and display errors
 	descriptors, rejected := filterInvalidDescriptors(descriptors)
+
+	// sort test suites by the declared order and dependencies
+	sorted, circular := script.SortDescriptors(descriptors)
+	rejected = append(rejected, circular...)
 	for _, d := range rejected {
 		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
 		r.outputPrinter.Println(r.outputPrinter.Section(d.Error.Error()))
+		r.reportTestSuite(&report.TestSuiteRecord{
+			Name: d.Locator.RelativePath,
+			Path: d.Locator.AbsolutePath,
+			StartTime: time.Now(),
+			TestCases: make([]*report.TestCaseRecord, 0),
+			Error: d.Error,
+		})
 	}
 
-	// filter testing script files by "inclusive-files"
-	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
-
-	// filter testing script files by "exclusive-files"
-	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
-
 	// begin testing
 	r.outputPrinter.Println()
 	r.outputPrinter.Println(r.outputPrinter.Heading("Testing"))
 
-	// create the test runners
-	internalTests, err2 := r.wrapTestSuites(descriptors)
-	if err2 != nil {
-		return err2
+	r.runTestSuites(sorted)
+
+	// keep the captured results for the later commands (e.g. gen curl --results)
+	if args != nil && len(args.GetSaveResults()) > 0 {
+		store := sieve.NewResultStore()
+		for _, descriptor := range sorted {
+			if descriptor.TestSuite != nil {
+				store.Collect(descriptor.Locator.AbsolutePath, descriptor.TestSuite.GetResultCache())
+			}
+		}
+		if err := store.Save(args.GetSaveResults()); err != nil {
+			fmt.Fprintln(os.Stderr, r.outputPrinter.ContextInfo("Results", err.Error()))
+		}
 	}
 
-	// summary
-	internalTests = append(internalTests, testing.InternalTest{
-		Name: "Summary",
-		F: func(t *testing.T) {
-			// summarize testing
-			r.outputPrinter.Println()
-			r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
+	// summarize testing
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
 
-			totalTestcases := (r.counter.Pending + r.counter.Skipped + r.counter.Cracked + r.counter.Failure + r.counter.Success)
-			totalFiles := len(descriptors)
-			r.outputPrinter.Printf("[*] Total: %d test case(s), in %d file(s)", totalTestcases, totalFiles)
-			r.outputPrinter.Println()
+	totalTestcases := (r.counter.Pending + r.counter.Skipped + r.counter.Cracked + r.counter.Failure + r.counter.Success)
+	totalFiles := len(sorted)
+	r.outputPrinter.Printf("[*] Total: %d test case(s), in %d file(s)", totalTestcases, totalFiles)
+	r.outputPrinter.Println()
 
-			r.outputPrinter.Printf("[*] Pending: %d, Skipped: %d, Cracked: %d, Failed: %d, Passed: %d",
-				r.counter.Pending, r.counter.Skipped, r.counter.Cracked, r.counter.Failure, r.counter.Success)
-			r.outputPrinter.Println()
+	r.outputPrinter.Printf("[*] Pending: %d, Skipped: %d, Cracked: %d, Failed: %d, Passed: %d",
+		r.counter.Pending, r.counter.Skipped, r.counter.Cracked, r.counter.Failure, r.counter.Success)
+	r.outputPrinter.Println()
 
-			// total elapsed time
-			duration := time.Since(startTime)
-			r.outputPrinter.Printf("[*] Elapsed time: %s", duration.String())
-			r.outputPrinter.Println()
+	if len(rejected) > 0 {
+		r.outputPrinter.Printf("[*] Invalid: %d file(s)", len(rejected))
+		r.outputPrinter.Println()
+	}
 
-			// endof testing
-			r.outputPrinter.Println()
-		},
-	})
+	if r.snapshots != nil {
+		r.outputPrinter.Printf("[*] Updated snapshots: %d", r.snapshots.updated)
+		r.outputPrinter.Println()
+	}
 
-	// Run the tests
-	if r.t != nil {
-		return runTests(r.t, internalTests)
+	// operations of the OpenAPI document which have never been exercised
+	var unexercised []string
+	if contract := r.specHandler.GetContractChecker(); contract != nil {
+		unexercised = make([]string, 0)
+		for _, op := range contract.GetUnexercisedOperations() {
+			unexercised = append(unexercised, op.String())
+		}
+		r.outputPrinter.Printf("[*] Unexercised operations: %d", len(unexercised))
+		r.outputPrinter.Println()
+		for _, op := range unexercised {
+			r.outputPrinter.Printf("    - %s", op)
+			r.outputPrinter.Println()
+		}
 	}
 
-	flag.Set("test.v", "false")
-	if false {
-		testing.MainStart(testDeps(defaultMatchString), internalTests, nil, nil).Run()
-	} else {
-		testing.Main(defaultMatchString, internalTests, nil, nil)
+	// total elapsed time
+	duration := time.Since(startTime)
+	r.outputPrinter.Printf("[*] Elapsed time: %s", duration.String())
+	r.outputPrinter.Println()
+
+	// write the reports
+	summary := &report.SummaryRecord{
+		StartTime: startTime,
+		Duration: duration,
+		TotalFiles: totalFiles,
+		Pending: r.counter.Pending,
+		Skipped: r.counter.Skipped,
+		Success: r.counter.Success,
+		Failure: r.counter.Failure,
+		Cracked: r.counter.Cracked,
+		Invalid: len(rejected),
+		UnexercisedOperations: unexercised,
 	}
+	r.notifyReporters(func(reporter report.Reporter) error {
+		return reporter.Close(summary)
+	})
 
-	return nil
+	// endof testing
+	r.outputPrinter.Println()
+
+	err := r.determineExitError(args, len(rejected))
+	if err != nil && r.t != nil {
+		r.t.Error(err)
+	}
+	return err
 }
 
-func runTests(t *testing.T, internalTests []testing.InternalTest) error {
-	for _, test := range internalTests {
-		test.F(t)
+func (r *RunController) determineExitError(args RunArguments, invalid int) error {
+	if invalid > 0 {
+		return NewExitError(EXIT_CODE_INVALID, fmt.Errorf("%d test suite file(s) are invalid", invalid))
+	}
+	if r.counter.Cracked > 0 {
+		return NewExitError(EXIT_CODE_CRACKED, fmt.Errorf("%d testcase(s) have cracked", r.counter.Cracked))
+	}
+	if r.counter.Failure > 0 {
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("%d testcase(s) have failed", r.counter.Failure))
+	}
+	if args != nil && args.GetFailOnPending() && r.counter.Pending > 0 {
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("%d testcase(s) are pending", r.counter.Pending))
+	}
+	if args != nil && args.GetFailOnSkipped() && r.counter.Skipped > 0 {
+		return NewExitError(EXIT_CODE_FAILURE, fmt.Errorf("%d testcase(s) are skipped", r.counter.Skipped))
 	}
 	return nil
 }
 
-type testDeps func(pat, str string) (bool, error)
-func (f testDeps) MatchString(pat, str string) (bool, error)   { return f(pat, str) }
-func (f testDeps) StartCPUProfile(w io.Writer) error           { return nil }
-func (f testDeps) StopCPUProfile()                             {}
-func (f testDeps) WriteProfileTo(string, io.Writer, int) error { return nil }
-func (f testDeps) ImportPath() string                          { return "" }
-func (f testDeps) StartTestLog(io.Writer)                      {}
-func (f testDeps) StopTestLog() error                          { return nil }
-
-func defaultMatchString(pat, str string) (bool, error) {
-	return true, nil
-}
+func (r *RunController) runTestSuites(descriptors []*script.Descriptor) {
+	if r.parallel <= 1 {
+		for _, descriptor := range descriptors {
+			r.runTestSuite(r.outputPrinter, descriptor, true)
+		}
+		return
+	}
 
-func (r *RunController) wrapTestSuites(descriptors map[string]*script.Descriptor) ([]testing.InternalTest, error) {
-	if r.specHandler == nil {
-		panic(fmt.Errorf("SpecHandler must not be nil"))
+	// run the test suites in a pool of workers, each suite has its own output buffer
+	outputs := make([]*suiteOutput, len(descriptors))
+	positions := make(map[*script.Descriptor]int, len(descriptors))
+	for i, descriptor := range descriptors {
+		outputs[i] = &suiteOutput{ buffer: new(bytes.Buffer), done: make(chan bool) }
+		positions[descriptor] = i
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < r.parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				// the dependencies are placed before, they have been started already
+				for _, dep := range descriptors[i].Dependencies {
+					if pos, ok := positions[dep]; ok {
+						<-outputs[pos].done
+					}
+				}
+				printer := r.outputPrinter.Clone()
+				printer.SetWriter(outputs[i].buffer)
+				outputs[i].record = r.runTestSuite(printer, descriptors[i], false)
+				close(outputs[i].done)
+			}
+		}()
 	}
-	tests := make([]testing.InternalTest, 0)
-	for _, descriptor := range descriptors {
-		test, err := r.wrapDescriptor(descriptor)
-		if err == nil {
-			tests = append(tests, test)
+	go func() {
+		for i := range descriptors {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	// flush the outputs & reports in the declared order
+	for _, output := range outputs {
+		<-output.done
+		r.outputPrinter.GetWriter().Write(output.buffer.Bytes())
+		if output.record != nil {
+			r.reportTestSuite(output.record)
 		}
 	}
-	return tests, nil
+	wg.Wait()
 }
 
-func (r *RunController) wrapDescriptor(descriptor *script.Descriptor) (testing.InternalTest, error) {
+type suiteOutput struct {
+	buffer *bytes.Buffer
+	record *report.TestSuiteRecord
+	done chan bool
+}
+
+func (r *RunController) runTestSuite(printer *format.OutputPrinter, descriptor *script.Descriptor, live bool) *report.TestSuiteRecord {
+	if r.specHandler == nil {
+		panic(fmt.Errorf("SpecHandler must not be nil"))
+	}
 	testsuite := descriptor.TestSuite
 	if testsuite == nil {
-		return testing.InternalTest{}, fmt.Errorf("TestSuite must not be nil")
+		return nil
 	}
-	return testing.InternalTest{
+
+	printer.Println(printer.TestSuiteTitle(descriptor.Locator.RelativePath))
+	for _, warning := range descriptor.Warnings {
+		printer.Println(printer.WarnMsg(warning))
+	}
+	suiteRecord := &report.TestSuiteRecord{
 		Name: descriptor.Locator.RelativePath,
-		F: func (t *testing.T) {
-			r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(descriptor.Locator.RelativePath))
-			tests := make([]testing.InternalTest, 0)
-			for _, testcase := range testsuite.TestCases {
-				tests = append(tests, r.wrapTestCase(testcase, testsuite.GetResultCache()))
+		Path: descriptor.Locator.AbsolutePath,
+		StartTime: time.Now(),
+		TestCases: make([]*report.TestCaseRecord, 0),
+	}
+	if live {
+		r.notifyReporters(func(reporter report.Reporter) error {
+			return reporter.StartTestSuite(suiteRecord)
+		})
+	}
+
+	cache := testsuite.GetResultCache()
+	cache.SetVariables(r.variables)
+	for _, batch := range splitTestCaseBatches(testsuite.TestCases, r.parallel > 1) {
+		for _, caseRecord := range r.runTestCaseBatch(printer, batch, cache) {
+			suiteRecord.TestCases = append(suiteRecord.TestCases, caseRecord)
+			if live {
+				r.notifyReporters(func(reporter report.Reporter) error {
+					return reporter.FinishTestCase(suiteRecord, caseRecord)
+				})
 			}
-			testing.RunTests(defaultMatchString, tests)
-		},
-	}, nil
+		}
+	}
+
+	suiteRecord.Duration = time.Since(suiteRecord.StartTime)
+	if live {
+		r.notifyReporters(func(reporter report.Reporter) error {
+			return reporter.FinishTestSuite(suiteRecord)
+		})
+	}
+	return suiteRecord
 }
 
-func (r *RunController) wrapTestCase(testcase *engine.TestCase, cache *sieve.RestCache) (testing.InternalTest) {
-	return testing.InternalTest{
-		Name: testcase.Title,
-		F: func (t *testing.T) {
-			if testcase.Pending != nil && *testcase.Pending {
-				r.outputPrinter.Println(r.outputPrinter.Pending(testcase.Title))
-				r.counter.Pending += 1
-				return
-			}
-			if !r.scriptSelector.IsMatched(testcase.Title) {
-				label := printUnmatchedPattern(r.outputPrinter, "unmatched")
-				r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), label)
-				r.counter.Skipped += 1
-				return
-			}
-			active, mark := r.tagManager.IsActive(testcase.Tags)
-			tagstr := printMarkedTags(r.outputPrinter, testcase.Tags, mark)
-			if !active {
-				r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr)
-				r.counter.Skipped += 1
-				return
-			}
+func (r *RunController) reportTestSuite(suiteRecord *report.TestSuiteRecord) {
+	caseRecords := suiteRecord.TestCases
+	suiteRecord.TestCases = make([]*report.TestCaseRecord, 0, len(caseRecords))
+	r.notifyReporters(func(reporter report.Reporter) error {
+		return reporter.StartTestSuite(suiteRecord)
+	})
+	for _, caseRecord := range caseRecords {
+		suiteRecord.TestCases = append(suiteRecord.TestCases, caseRecord)
+		r.notifyReporters(func(reporter report.Reporter) error {
+			return reporter.FinishTestCase(suiteRecord, caseRecord)
+		})
+	}
+	r.notifyReporters(func(reporter report.Reporter) error {
+		return reporter.FinishTestSuite(suiteRecord)
+	})
+}
 
-			result, err := r.specHandler.Examine(testcase, cache)
-			if result == nil {
-				panic(fmt.Errorf("Result of Examine() must not be nil"))
-			}
+func (r *RunController) runTestCaseBatch(printer *format.OutputPrinter, batch []*engine.TestCase, cache *sieve.RestCache) []*report.TestCaseRecord {
+	records := make([]*report.TestCaseRecord, len(batch))
+	if len(batch) == 1 {
+		records[0] = r.runTestCase(printer, batch[0], cache)
+		return records
+	}
+
+	// run the testcases concurrently and print their output in the declared order
+	buffers := make([]*bytes.Buffer, len(batch))
+	var wg sync.WaitGroup
+	for i, testcase := range batch {
+		buffers[i] = new(bytes.Buffer)
+		wg.Add(1)
+		go func(i int, testcase *engine.TestCase) {
+			defer wg.Done()
+			casePrinter := printer.Clone()
+			casePrinter.SetWriter(buffers[i])
+			records[i] = r.runTestCase(casePrinter, testcase, cache)
+		}(i, testcase)
+	}
+	wg.Wait()
+	for _, buf := range buffers {
+		printer.GetWriter().Write(buf.Bytes())
+	}
+	return records
+}
+
+func splitTestCaseBatches(testcases []*engine.TestCase, parallel bool) [][]*engine.TestCase {
+	batches := make([][]*engine.TestCase, 0)
+	var batch []*engine.TestCase
+	for _, testcase := range testcases {
+		if parallel && isParallelizable(testcase) {
+			batch = append(batch, testcase)
+			continue
+		}
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		batches = append(batches, []*engine.TestCase{testcase})
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func isParallelizable(testcase *engine.TestCase) bool {
+	if testcase == nil || testcase.Parallel == nil || !*testcase.Parallel {
+		return false
+	}
+	// the testcases which capture into or read from the result cache keep their order
+	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
+		return false
+	}
+	if testcase.Request != nil && sieve.HasResultReferences(testcase.Request) {
+		return false
+	}
+	return true
+}
+
+func (r *RunController) runTestCase(printer *format.OutputPrinter, testcase *engine.TestCase, cache *sieve.RestCache) *report.TestCaseRecord {
+	if r.limiter != nil {
+		r.limiter <- true
+		defer func() { <-r.limiter }()
+	}
+	record := r.examineAndPrint(printer, testcase, cache)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch(record.Status) {
+	case report.STATUS_PENDING:
+		r.counter.Pending += 1
+	case report.STATUS_SKIPPED:
+		r.counter.Skipped += 1
+	case report.STATUS_CRACKED:
+		r.counter.Cracked += 1
+	case report.STATUS_FAILURE:
+		r.counter.Failure += 1
+	case report.STATUS_SUCCESS:
+		r.counter.Success += 1
+	}
+	return record
+}
+
+func (r *RunController) examineAndPrint(printer *format.OutputPrinter, testcase *engine.TestCase, cache *sieve.RestCache) *report.TestCaseRecord {
+	record := &report.TestCaseRecord{
+		Title: testcase.Title,
+		Tags: testcase.Tags,
+	}
+	if testcase.Pending != nil && *testcase.Pending {
+		printer.Println(printer.Pending(testcase.Title))
+		record.Status = report.STATUS_PENDING
+		return record
+	}
+	if !r.scriptSelector.IsMatched(testcase.Title) {
+		label := printUnmatchedPattern(printer, "unmatched")
+		printer.Println(printer.Skipped(testcase.Title), label)
+		record.Status = report.STATUS_SKIPPED
+		record.Reason = "unmatched"
+		return record
+	}
+	active, mark := r.tagManager.IsActive(testcase.Tags)
+	tagstr := printMarkedTags(printer, testcase.Tags, mark)
+	if !active {
+		printer.Println(printer.Skipped(testcase.Title), tagstr)
+		record.Status = report.STATUS_SKIPPED
+		record.Reason = "tags"
+		return record
+	}
 
-			exectime := printDuration(r.outputPrinter, result.Duration)
-			if err != nil {
-				r.outputPrinter.Println(r.outputPrinter.Cracked(testcase.Title), tagstr, exectime)
-				r.printErrorMap(result.Errors)
-				r.counter.Cracked += 1
-				return
+	result, err := r.examineTestCase(testcase, cache)
+	record.Duration = result.Duration
+	record.Errors = result.Errors
+	record.Attempts = result.Attempts
+	record.LastFailure = result.LastFailure
+	if result.Request != nil {
+		record.Request = &report.RequestSummary{
+			Method: result.Request.Method,
+			Url: client.BuildUrl(result.Request),
+		}
+	}
+	if result.Response != nil {
+		record.Response = &report.ResponseSummary{
+			Status: result.Response.Status,
+			StatusCode: result.Response.StatusCode,
+			ContentType: result.Response.Header.Get("Content-Type"),
+			ContentLength: int64(len(result.Response.Body)),
+		}
+	}
+
+	exectime := printDuration(printer, result.Duration)
+	if result.Attempts > 1 {
+		exectime = fmt.Sprintf("%s (%d attempts)", exectime, result.Attempts)
+	}
+	if err != nil {
+		printer.Println(printer.Cracked(testcase.Title), tagstr, exectime)
+		printErrorMap(printer, result.Errors)
+		printLastFailure(printer, result.LastFailure)
+		record.Status = report.STATUS_CRACKED
+		return record
+	}
+	if len(result.Errors) > 0 && r.snapshots != nil && r.snapshots.accepts(testcase, result) {
+		diff, err := r.snapshots.update(testcase, result)
+		if err != nil {
+			result.Errors["Snapshot"] = err
+		} else {
+			result.Errors = r.snapshots.remainingErrors(result.Errors)
+			record.Errors = result.Errors
+			if len(result.Errors) == 0 {
+				printer.Println(printer.Success(testcase.Title), tagstr, printSnapshotLabel(printer), exectime)
+				printDiff(printer, diff)
+				record.Status = report.STATUS_SUCCESS
+				record.Reason = "snapshot-updated"
+				return record
 			}
-			if len(result.Errors) > 0 {
-				r.outputPrinter.Println(r.outputPrinter.Failure(testcase.Title), tagstr, exectime)
-				r.printErrorMap(result.Errors)
-				r.counter.Failure += 1
-				return
+			printer.Println(printer.Failure(testcase.Title), tagstr, printSnapshotLabel(printer), exectime)
+			printDiff(printer, diff)
+			printErrorMap(printer, result.Errors)
+			printLastFailure(printer, result.LastFailure)
+			record.Status = report.STATUS_FAILURE
+			return record
+		}
+	}
+	if len(result.Errors) > 0 {
+		printer.Println(printer.Failure(testcase.Title), tagstr, exectime)
+		printErrorMap(printer, result.Errors)
+		printLastFailure(printer, result.LastFailure)
+		record.Status = report.STATUS_FAILURE
+		return record
+	}
+	printer.Println(printer.Success(testcase.Title), tagstr, exectime)
+	record.Status = report.STATUS_SUCCESS
+	return record
+}
+
+func (r *RunController) examineTestCase(testcase *engine.TestCase, cache *sieve.RestCache) (result *engine.ExaminationResult, err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			result = &engine.ExaminationResult{
+				Duration: time.Since(startTime),
+				Status: "error",
+				Errors: map[string]error{
+					"Panic": fmt.Errorf("%v", e),
+				},
 			}
-			r.outputPrinter.Println(r.outputPrinter.Success(testcase.Title), tagstr, exectime)
-			r.counter.Success += 1
-		},
+			err = fmt.Errorf("Testcase has cracked, error: %v", e)
+		}
+	}()
+	result, err = r.specHandler.Examine(testcase, cache)
+	if result == nil {
+		panic(fmt.Errorf("Result of Examine() must not be nil"))
 	}
+	return result, err
 }
 
-func (r *RunController) printErrorMap(errorKV map[string]error) {
-	for key, err := range errorKV {
-		r.outputPrinter.Printf(r.outputPrinter.SectionTitle(key))
-		r.outputPrinter.Printf(r.outputPrinter.Section(err.Error()))
-		r.outputPrinter.Println()
+func (r *RunController) notifyReporters(notify func(reporter report.Reporter) error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, reporter := range r.reporters {
+		if err := notify(reporter); err != nil {
+			fmt.Fprintln(os.Stderr, r.outputPrinter.ContextInfo("Report", err.Error()))
+		}
+	}
+}
+
+const OUTPUT_FORMAT_TEXT = "text"
+
+func printLastFailure(printer *format.OutputPrinter, lastFailure error) {
+	if lastFailure == nil {
+		return
+	}
+	printer.Printf(printer.SectionTitle("Last failure"))
+	printer.Printf(printer.Section(lastFailure.Error()))
+	printer.Println()
+}
+
+func printErrorMap(printer *format.OutputPrinter, errorKV map[string]error) {
+	keys := make([]string, 0, len(errorKV))
+	for key := range errorKV {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		err := errorKV[key]
+		printer.Printf(printer.SectionTitle(key))
+		printer.Printf(printer.Section(err.Error()))
+		printer.Println()
 	}
 }
\ No newline at end of file
@@ -0,0 +1,343 @@
+package bootstrap
+
+import(
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+type runOptionsStub struct {
+	testDirs []string
+	pdp string
+}
+
+func (o *runOptionsStub) GetTestDirs() []string { return o.testDirs }
+func (o *runOptionsStub) GetInclFiles() []string { return nil }
+func (o *runOptionsStub) GetExclFiles() []string { return nil }
+func (o *runOptionsStub) GetTestName() string { return "" }
+func (o *runOptionsStub) GetConditionalTags() []string { return nil }
+func (o *runOptionsStub) GetPDP() string { return o.pdp }
+func (o *runOptionsStub) GetTimeout() string { return "" }
+func (o *runOptionsStub) GetTransport() *client.HttpTransportOptions { return nil }
+func (o *runOptionsStub) GetOpenAPI() string { return "" }
+func (o *runOptionsStub) GetConfigPath() string { return "" }
+func (o *runOptionsStub) GetEnv() string { return "" }
+func (o *runOptionsStub) GetVariables() map[string]string { return nil }
+func (o *runOptionsStub) GetNoColor() bool { return true }
+func (o *runOptionsStub) GetSnapshotProfile() *engine.GenerationProfile { return nil }
+
+type runArgumentsStub struct {
+	failOnPending bool
+	failOnSkipped bool
+	outputFormat string
+	reportFormat string
+	reportFile string
+	parallel int
+	updateSnapshots bool
+	saveResults string
+}
+
+func (a *runArgumentsStub) GetOutputFormat() string { return a.outputFormat }
+func (a *runArgumentsStub) GetReportFormat() string { return a.reportFormat }
+func (a *runArgumentsStub) GetReportFile() string { return a.reportFile }
+func (a *runArgumentsStub) GetFailOnPending() bool { return a.failOnPending }
+func (a *runArgumentsStub) GetFailOnSkipped() bool { return a.failOnSkipped }
+func (a *runArgumentsStub) GetParallel() int { return a.parallel }
+func (a *runArgumentsStub) GetUpdateSnapshots() bool { return a.updateSnapshots }
+func (a *runArgumentsStub) GetSaveResults() string { return a.saveResults }
+
+func newRunControllerForTest(t *testing.T, dir string, pdp string) *RunController {
+	r, err := NewRunController(&runOptionsStub{ testDirs: []string{ dir }, pdp: pdp })
+	assert.Nil(t, err)
+	r.GetOutputPrinter().SetWriter(ioutil.Discard)
+	return r
+}
+
+func TestRunController_determineExitError(t *testing.T) {
+	TESTCASES := []struct {
+		args *runArgumentsStub
+		invalid int
+		pending int
+		skipped int
+		failure int
+		cracked int
+		code int
+	}{
+		{
+			args: &runArgumentsStub{},
+			code: EXIT_CODE_SUCCESS,
+		},
+		{
+			args: &runArgumentsStub{},
+			pending: 1,
+			skipped: 1,
+			code: EXIT_CODE_SUCCESS,
+		},
+		{
+			args: &runArgumentsStub{ failOnPending: true },
+			pending: 1,
+			code: EXIT_CODE_FAILURE,
+		},
+		{
+			args: &runArgumentsStub{ failOnSkipped: true },
+			skipped: 2,
+			code: EXIT_CODE_FAILURE,
+		},
+		{
+			args: &runArgumentsStub{},
+			failure: 1,
+			code: EXIT_CODE_FAILURE,
+		},
+		{
+			args: &runArgumentsStub{},
+			failure: 1,
+			cracked: 1,
+			code: EXIT_CODE_CRACKED,
+		},
+		{
+			args: &runArgumentsStub{},
+			invalid: 1,
+			failure: 1,
+			cracked: 1,
+			code: EXIT_CODE_INVALID,
+		},
+	}
+	for _, TEST := range TESTCASES {
+		r := &RunController{}
+		r.counter.Pending = TEST.pending
+		r.counter.Skipped = TEST.skipped
+		r.counter.Failure = TEST.failure
+		r.counter.Cracked = TEST.cracked
+		err := r.determineExitError(TEST.args, TEST.invalid)
+		if TEST.code == EXIT_CODE_SUCCESS {
+			assert.Nil(t, err)
+			continue
+		}
+		if assert.NotNil(t, err) {
+			assert.Equal(t, TEST.code, err.(*ExitError).ExitCode())
+		}
+	}
+}
+
+func TestRunController_splitTestCaseBatches(t *testing.T) {
+	yes := true
+	testcases := []*engine.TestCase{
+		{ Title: "create user", Capture: &engine.SectionCapture{ StoreID: "create-user" } },
+		{ Title: "list users", Parallel: &yes, Request: &client.HttpRequest{ Path: "/v1/users" } },
+		{ Title: "count users", Parallel: &yes, Request: &client.HttpRequest{ Path: "/v1/users/count" } },
+		{ Title: "get user", Parallel: &yes, Request: &client.HttpRequest{ Path: "/v1/users/${{ case[create-user].Body[id] }}" } },
+		{ Title: "list roles", Parallel: &yes, Request: &client.HttpRequest{ Path: "/v1/roles" } },
+	}
+	titles := func(batches [][]*engine.TestCase) [][]string {
+		result := make([][]string, 0)
+		for _, batch := range batches {
+			names := make([]string, 0)
+			for _, testcase := range batch {
+				names = append(names, testcase.Title)
+			}
+			result = append(result, names)
+		}
+		return result
+	}
+	assert.Equal(t, [][]string{
+		{ "create user" }, { "list users", "count users" }, { "get user" }, { "list roles" },
+	}, titles(splitTestCaseBatches(testcases, true)))
+	assert.Equal(t, 5, len(splitTestCaseBatches(testcases, false)))
+}
+
+func TestRunController_Execute_Parallel(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "opwire-testa-parallel")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	for i := 0; i < 3; i++ {
+		var suite strings.Builder
+		suite.WriteString("testcases:\n")
+		for j := 0; j < 4; j++ {
+			path := "/ok"
+			if i == 0 && j == 0 {
+				path = "/missing"
+			}
+			suite.WriteString(fmt.Sprintf("  - title: testcase %d\n    parallel: true\n    request:\n      method: GET\n      path: %s\n", j, path))
+			suite.WriteString("    expectation:\n      status-code:\n        is:\n          equal-to: 200\n")
+		}
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("suite%d_test.yml", i)), []byte(suite.String()), 0644))
+	}
+
+	reportFile := filepath.Join(dir, "report.json")
+	r := newRunControllerForTest(t, dir, server.URL)
+	err = r.Execute(&runArgumentsStub{ parallel: 2, reportFormat: "json", reportFile: reportFile })
+	if assert.NotNil(t, err) {
+		assert.Equal(t, EXIT_CODE_FAILURE, err.(*ExitError).ExitCode())
+	}
+	assert.Equal(t, 11, r.counter.Success)
+	assert.Equal(t, 1, r.counter.Failure)
+	assert.True(t, maxInFlight <= 2, "%d requests were in flight", maxInFlight)
+
+	var doc struct {
+		Suites []struct {
+			Name string `json:"name"`
+			TestCases []struct {
+				Title string `json:"title"`
+				Status string `json:"status"`
+			} `json:"testcases"`
+		} `json:"suites"`
+	}
+	content, err := ioutil.ReadFile(reportFile)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(content, &doc))
+	if assert.Equal(t, 3, len(doc.Suites)) {
+		for i, suite := range doc.Suites {
+			assert.Equal(t, fmt.Sprintf("suite%d_test.yml", i), filepath.Base(suite.Name))
+			if assert.Equal(t, 4, len(suite.TestCases)) {
+				for j, testcase := range suite.TestCases {
+					assert.Equal(t, fmt.Sprintf("testcase %d", j), testcase.Title)
+				}
+			}
+		}
+		assert.Equal(t, "failure", doc.Suites[0].TestCases[0].Status)
+	}
+}
+
+func TestRunController_Execute_UpdateSnapshots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{ "name": "new" }`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "opwire-testa-snapshots")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	suitePath := filepath.Join(dir, "users_test.yml")
+	assert.Nil(t, ioutil.WriteFile(suitePath, []byte(`# the recorded snapshots
+testcases:
+  - title: snapshot user
+    request:
+      method: GET
+      path: /users/1
+    expectation:
+      status-code:
+        is:
+          equal-to: 200
+      body:
+        has-format: json
+        includes: '{ "name": "old" }'
+    tags:
+    - snapshot
+  - title: regular user
+    request:
+      method: GET
+      path: /users/2
+    expectation:
+      body:
+        has-format: json
+        includes: '{ "name": "old" }'
+`), 0644))
+
+	r := newRunControllerForTest(t, dir, server.URL)
+	err = r.Execute(&runArgumentsStub{ updateSnapshots: true })
+	if assert.NotNil(t, err) {
+		assert.Equal(t, EXIT_CODE_FAILURE, err.(*ExitError).ExitCode())
+	}
+	// only the testcase tagged "snapshot" is re-recorded
+	assert.Equal(t, 1, r.counter.Success)
+	assert.Equal(t, 1, r.counter.Failure)
+	assert.Equal(t, 1, r.snapshots.updated)
+
+	content, err := ioutil.ReadFile(suitePath)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "# the recorded snapshots\ntestcases:\n  - title: snapshot user\n"))
+	assert.Equal(t, 1, strings.Count(string(content), `"name": "old"`))
+	assert.Contains(t, string(content), `"name": "new"`)
+
+	// the updated snapshot passes
+	r = newRunControllerForTest(t, dir, server.URL)
+	assert.NotNil(t, r.Execute(&runArgumentsStub{}))
+	assert.Equal(t, 1, r.counter.Success)
+	assert.Equal(t, 1, r.counter.Failure)
+
+	// the violations of the API contract are kept
+	remaining := r.snapshots.remainingErrors(map[string]error{
+		"Body/Includes": fmt.Errorf("mismatched"),
+		"Contract/StatusCode": fmt.Errorf("undeclared"),
+	})
+	assert.Equal(t, 1, len(remaining))
+	assert.NotNil(t, remaining["Contract/StatusCode"])
+}
+
+func TestRunController_Execute_InvalidSuites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-invalid")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "broken_test.yml"), []byte("testcases: [\n"), 0644))
+	reportPath := filepath.Join(dir, "report.xml")
+
+	r := newRunControllerForTest(t, dir, "")
+	err = r.Execute(&runArgumentsStub{ reportFormat: "junit", reportFile: reportPath })
+	if assert.NotNil(t, err) {
+		assert.Equal(t, EXIT_CODE_INVALID, err.(*ExitError).ExitCode())
+	}
+
+	// the rejected test suites are reported as errors
+	content, err := ioutil.ReadFile(reportPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), `<testsuites name="opwire-testa" tests="1" failures="0" errors="1"`)
+	assert.Contains(t, string(content), `broken_test.yml" tests="1" failures="0" errors="1"`)
+	assert.Contains(t, string(content), `<error message="Test suite is invalid" type="invalid">`)
+}
+
+func TestRunController_Execute_JSONOutputAndReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-reports")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	r := newRunControllerForTest(t, dir, "")
+	err = r.Execute(&runArgumentsStub{ outputFormat: "json", reportFormat: "jsonl" })
+	if assert.NotNil(t, err) {
+		assert.Equal(t, EXIT_CODE_INVALID, err.(*ExitError).ExitCode())
+		assert.Contains(t, err.Error(), "requires a --report-file")
+	}
+}
+
+func TestRunController_Execute_ReportFileWithoutFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-reports")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	r := newRunControllerForTest(t, dir, "")
+	err = r.Execute(&runArgumentsStub{ reportFile: filepath.Join(dir, "report.xml") })
+	if assert.NotNil(t, err) {
+		assert.Equal(t, EXIT_CODE_INVALID, err.(*ExitError).ExitCode())
+		assert.Contains(t, err.Error(), "requires a report format")
+	}
+}
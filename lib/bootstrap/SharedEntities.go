@@ -1,8 +1,10 @@
 package bootstrap
 
 import (
+	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/engine"
@@ -12,6 +14,33 @@ import (
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
+const (
+	EXIT_CODE_SUCCESS int = 0
+	EXIT_CODE_FAILURE int = 1
+	EXIT_CODE_INVALID int = 2
+	EXIT_CODE_CRACKED int = 3
+)
+
+type ExitError struct {
+	code int
+	err error
+}
+
+func NewExitError(code int, err error) *ExitError {
+	if err == nil {
+		err = fmt.Errorf("Exit with code [%d]", code)
+	}
+	return &ExitError{ code: code, err: err }
+}
+
+func (e *ExitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.code
+}
+
 func printUnmatchedPattern(outputPrinter *format.OutputPrinter, label string) string {
 	if outputPrinter.IsColorized() {
 		label = outputPrinter.NegativeTag(label)
@@ -100,6 +129,9 @@ func filterInvalidDescriptors(src map[string]*script.Descriptor) (map[string]*sc
 			rejected = append(rejected, d)
 		}
 	}
+	sort.Slice(rejected, func(i, j int) bool {
+		return rejected[i].Locator.AbsolutePath < rejected[j].Locator.AbsolutePath
+	})
 	return selected, rejected
 }
 
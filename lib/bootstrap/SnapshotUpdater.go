@@ -0,0 +1,94 @@
+package bootstrap
+
+import (
+	"strings"
+	"sync"
+	"gopkg.in/yaml.v2"
+	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type snapshotUpdater struct {
+	specBuilder *engine.SpecBuilder
+	scriptWriter *script.Writer
+	updated int
+	mutex sync.Mutex
+}
+
+func newSnapshotUpdater(profile *engine.GenerationProfile) (u *snapshotUpdater, err error) {
+	u = &snapshotUpdater{}
+	u.specBuilder, err = engine.NewSpecBuilder()
+	if err != nil {
+		return nil, err
+	}
+	if err = u.specBuilder.ApplyProfile(profile); err != nil {
+		return nil, err
+	}
+	u.scriptWriter, err = script.NewWriter()
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// only the failed testcases tagged "snapshot" which have got a response are re-recorded
+func (u *snapshotUpdater) accepts(testcase *engine.TestCase, result *engine.ExaminationResult) bool {
+	if result.Response == nil || len(testcase.GetSourcePath()) == 0 {
+		return false
+	}
+	if !utils.Contains(testcase.Tags, engine.SNAPSHOT_TAG) {
+		return false
+	}
+	return len(u.remainingErrors(result.Errors)) < len(result.Errors)
+}
+
+// rewrites the expectation of the testcase from the response, returns the changed lines
+func (u *snapshotUpdater) update(testcase *engine.TestCase, result *engine.ExaminationResult) ([]string, error) {
+	expectation := u.specBuilder.GenerateExpectation(result.Response)
+	if err := u.scriptWriter.UpdateExpectation(testcase.GetSourcePath(), testcase.Title, expectation); err != nil {
+		return nil, err
+	}
+	before, _ := yaml.Marshal(testcase.Expectation)
+	after, _ := yaml.Marshal(expectation)
+	testcase.Expectation = expectation
+
+	u.mutex.Lock()
+	u.updated += 1
+	u.mutex.Unlock()
+
+	return utils.DiffLines(strings.Split(strings.TrimRight(string(before), "\n"), "\n"),
+		strings.Split(strings.TrimRight(string(after), "\n"), "\n")), nil
+}
+
+// the violations of the API contract are not fixed by re-recording the expectation
+func (u *snapshotUpdater) remainingErrors(errors map[string]error) map[string]error {
+	remaining := make(map[string]error)
+	for key, err := range errors {
+		if strings.HasPrefix(key, "Contract/") {
+			remaining[key] = err
+		}
+	}
+	return remaining
+}
+
+func printSnapshotLabel(printer *format.OutputPrinter) string {
+	label := "snapshot updated"
+	if printer.IsColorized() {
+		label = printer.PositiveTag(label)
+	}
+	return "(" + label + ")"
+}
+
+func printDiff(printer *format.OutputPrinter, diff []string) {
+	printer.Println(printer.SectionTitle("Snapshot"))
+	for _, line := range diff {
+		if strings.HasPrefix(line, "+") {
+			line = printer.InfoMsg(line)
+		} else {
+			line = printer.WarnMsg(line)
+		}
+		printer.Println("    " + line)
+	}
+}
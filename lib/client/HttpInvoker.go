@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
@@ -15,16 +16,41 @@ type HttpInvoker interface {
 
 type HttpInvokerOptions struct {
 	PDP string
+	Timeout string
+	Transport *HttpTransportOptions
 }
 
 type HttpInvokerImpl struct {
 	pdp string
+	timeout time.Duration
+	transport *http.Transport
+	checkRedirect func(req *http.Request, via []*http.Request) error
 }
 
 func NewHttpInvoker(opts *HttpInvokerOptions) (c *HttpInvokerImpl, err error) {
 	c = &HttpInvokerImpl{}
 	if opts != nil {
 		c.pdp = opts.PDP
+		if len(opts.Timeout) > 0 {
+			c.timeout, err = time.ParseDuration(opts.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid default timeout [%s], error: %s", opts.Timeout, err)
+			}
+		}
+	}
+	var transportOpts *HttpTransportOptions
+	if opts != nil {
+		transportOpts = opts.Transport
+	}
+	c.transport, err = newHttpTransport(transportOpts)
+	if err != nil {
+		return nil, err
+	}
+	if transportOpts != nil {
+		c.checkRedirect, err = newRedirectPolicy(transportOpts.Redirects)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return c, nil
 }
@@ -34,11 +60,13 @@ func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*Ht
 		return nil, fmt.Errorf("Request must not be nil")
 	}
 
-	if len(req.PDP) == 0 {
+	if len(req.PDP) == 0 && len(c.pdp) > 0 {
 		req.PDP = c.pdp
+		// the raw request may have been built with the default PDP
+		req.request = nil
 	}
 
-	var reqTimeout time.Duration
+	var reqTimeout time.Duration = c.timeout
 	if req.Timeout != nil {
 		var err error
 		reqTimeout, err = time.ParseDuration(*req.Timeout)
@@ -48,6 +76,8 @@ func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*Ht
 	}
 
 	var httpClient *http.Client = &http.Client{
+		Transport: c.transport,
+		CheckRedirect: c.checkRedirect,
 		Timeout: reqTimeout,
 	}
 
@@ -100,6 +130,9 @@ func BuildUrl(req *HttpRequest) string {
 		}
 		url, _ = utils.UrlJoin(pdp, basePath)
 	}
+	if len(req.Query) > 0 {
+		url = AppendQuery(url, req.Query)
+	}
 	return url
 }
 
@@ -113,12 +146,35 @@ type HttpRequest struct {
 	Url string `yaml:"url,omitempty" json:"url"`
 	PDP string `yaml:"pdp,omitempty" json:"pdp"`
 	Path string `yaml:"path,omitempty" json:"path"`
+	Query []HttpQueryParam `yaml:"query,omitempty" json:"query"`
 	Headers []HttpHeader `yaml:"headers,omitempty" json:"headers"`
 	Body string `yaml:"body,omitempty" json:"body"`
+	BodyFile string `yaml:"body-file,omitempty" json:"body-file"`
+	Form []HttpFormField `yaml:"form,omitempty" json:"form"`
+	Multipart []HttpFormPart `yaml:"multipart,omitempty" json:"multipart"`
 	Timeout *string `yaml:"timeout,omitempty" json:"timeout"`
+	baseDir string
 	request *http.Request
 }
 
+type HttpQueryParam struct {
+	Name string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+type HttpFormField struct {
+	Name string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+type HttpFormPart struct {
+	Name string `yaml:"name" json:"name"`
+	Value string `yaml:"value,omitempty" json:"value"`
+	File string `yaml:"file,omitempty" json:"file"`
+	Filename string `yaml:"filename,omitempty" json:"filename"`
+	ContentType string `yaml:"content-type,omitempty" json:"content-type"`
+}
+
 func (r *HttpRequest) GetRawRequest() (req *http.Request, err error) {
 	if r.request == nil {
 		url := BuildUrl(r)
@@ -127,16 +183,17 @@ func (r *HttpRequest) GetRawRequest() (req *http.Request, err error) {
 		if len(r.Method) > 0 {
 			method = r.Method
 		}
+		// the methods are case-sensitive, a lower-case "head" would wait for a response body
+		if method != http.MethodHead && strings.EqualFold(method, http.MethodHead) {
+			return nil, fmt.Errorf("The method [%s] must be written as [%s]", method, http.MethodHead)
+		}
 
-		var body *bytes.Buffer
-
-		if len(r.Body) > 0 {
-			body = bytes.NewBufferString(r.Body)
-		} else {
-			body = bytes.NewBuffer([]byte{})
+		body, contentType, err := r.buildBody()
+		if err != nil {
+			return nil, err
 		}
 
-		req, err = http.NewRequest(method, url, body)
+		req, err = http.NewRequest(method, url, bytes.NewBuffer(body))
 		if err != nil {
 			return nil, err
 		}
@@ -146,6 +203,10 @@ func (r *HttpRequest) GetRawRequest() (req *http.Request, err error) {
 				req.Header.Add(header.Name, header.Value)
 			}
 		}
+		// the boundary of the multipart body is always given
+		if len(contentType) > 0 && (len(r.Multipart) > 0 || len(req.Header.Get("Content-Type")) == 0) {
+			req.Header.Set("Content-Type", contentType)
+		}
 
 		r.request = req
 	}
@@ -174,17 +235,30 @@ func NewHttpResponse(lowRes *http.Response) (res *HttpResponse, err error) {
 	res.StatusCode = lowRes.StatusCode
 	res.Header = lowRes.Header
 
+	res.response = lowRes
+
+	// the Content-Length of a bodiless response is the length of the omitted body
 	res.ContentLength = lowRes.ContentLength
+	if res.IsBodiless() || lowRes.Body == nil {
+		res.Body = []byte{}
+		return res, nil
+	}
 	res.Body, err = ioutil.ReadAll(lowRes.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	res.response = lowRes
-
 	return res, nil
 }
 
+// the responses to HEAD requests and the 1xx, 204 & 304 responses have no body
+func (r *HttpResponse) IsBodiless() bool {
+	if r.response != nil && r.response.Request != nil && r.response.Request.Method == http.MethodHead {
+		return true
+	}
+	return (r.StatusCode >= 100 && r.StatusCode < 200) || r.StatusCode == http.StatusNoContent || r.StatusCode == http.StatusNotModified
+}
+
 func (r *HttpResponse) GetRawResponse() (res *http.Response, err error) {
 	if r.response == nil {
 		return nil, fmt.Errorf("The original http.Reponse must not be nil")
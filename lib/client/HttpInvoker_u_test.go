@@ -0,0 +1,72 @@
+package client
+
+import(
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpInvoker_Methods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		switch(r.Method) {
+		case http.MethodOptions:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PROPFIND")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{})
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{ Method: "HEAD", Url: server.URL })
+	assert.Nil(t, err)
+	assert.True(t, res.IsBodiless())
+	assert.Equal(t, 0, len(res.Body))
+	assert.Equal(t, int64(5), res.ContentLength)
+
+	_, err = invoker.Do(&HttpRequest{ Method: "head", Url: server.URL })
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "The method [head] must be written as [HEAD]")
+	}
+
+	res, err = invoker.Do(&HttpRequest{ Method: "propfind", Url: server.URL })
+	assert.Nil(t, err)
+	assert.Equal(t, "propfind", res.Header.Get("X-Method"))
+
+	res, err = invoker.Do(&HttpRequest{ Method: "OPTIONS", Url: server.URL })
+	assert.Nil(t, err)
+	assert.True(t, res.IsBodiless())
+	assert.Equal(t, "GET, PROPFIND", res.Header.Get("Access-Control-Allow-Methods"))
+
+	res, err = invoker.Do(&HttpRequest{ Method: "PROPFIND", Url: server.URL })
+	assert.Nil(t, err)
+	assert.False(t, res.IsBodiless())
+	assert.Equal(t, "PROPFIND", res.Header.Get("X-Method"))
+	assert.Equal(t, "hello", string(res.Body))
+
+	_, err = invoker.Do(&HttpRequest{ Method: "BAD VERB", Url: server.URL })
+	assert.NotNil(t, err)
+}
+
+func TestHttpInvoker_DefaultPDP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ PDP: server.URL })
+	assert.Nil(t, err)
+	req := &HttpRequest{ Method: "GET", Path: "/pets" }
+	// the request has been validated (& built) before it is sent
+	_, err = req.GetRawRequest()
+	assert.Nil(t, err)
+	res, err := invoker.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "/pets", string(res.Body))
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var queryExpressionRe = regexp.MustCompile(`\$\{\{[^}]*\}\}`)
+
+// appends the parameters (in their order) to the query string of the URL, before the fragment
+func AppendQuery(rawUrl string, params []HttpQueryParam) string {
+	fragment := ""
+	if i := strings.Index(rawUrl, "#"); i >= 0 {
+		rawUrl, fragment = rawUrl[:i], rawUrl[i:]
+	}
+	pairs := make([]string, 0, len(params))
+	for _, param := range params {
+		pairs = append(pairs, escapeQueryComponent(param.Name) + "=" + escapeQueryComponent(param.Value))
+	}
+	separator := "?"
+	if strings.HasSuffix(rawUrl, "?") || strings.HasSuffix(rawUrl, "&") {
+		separator = ""
+	} else if strings.Contains(rawUrl, "?") {
+		separator = "&"
+	}
+	return rawUrl + separator + strings.Join(pairs, "&") + fragment
+}
+
+// the unresolved ${{...}} expressions are kept as they are (e.g. by the generators)
+func escapeQueryComponent(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range queryExpressionRe.FindAllStringIndex(text, -1) {
+		b.WriteString(url.QueryEscape(text[last:loc[0]]))
+		b.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(url.QueryEscape(text[last:]))
+	return b.String()
+}
+
+// splits the query string of the URL into parameters, false if it cannot be restored as is
+func SplitQuery(rawUrl string) (string, []HttpQueryParam, bool) {
+	i := strings.Index(rawUrl, "?")
+	if i < 0 || strings.Contains(rawUrl, "#") {
+		return rawUrl, nil, false
+	}
+	params := make([]HttpQueryParam, 0)
+	for _, pair := range strings.Split(rawUrl[i + 1:], "&") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return rawUrl, nil, false
+		}
+		name, err := url.QueryUnescape(kv[0])
+		if err != nil || len(name) == 0 {
+			return rawUrl, nil, false
+		}
+		value, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			return rawUrl, nil, false
+		}
+		params = append(params, HttpQueryParam{ Name: name, Value: value })
+	}
+	// e.g. "a%20b" is rebuilt as "a+b", "a=b=c" as "a=b%3Dc"
+	if AppendQuery(rawUrl[:i], params) != rawUrl {
+		return rawUrl, nil, false
+	}
+	return rawUrl[:i], params, true
+}
@@ -0,0 +1,51 @@
+package client
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildUrl_Query(t *testing.T) {
+	query := []HttpQueryParam{
+		{ Name: "tag", Value: "a&b" },
+		{ Name: "tag", Value: "c d" },
+		{ Name: "owner", Value: "${{ case[login].Body[id] }}" },
+	}
+	assert.Equal(t, "http://localhost/pets?tag=a%26b&tag=c+d&owner=${{ case[login].Body[id] }}",
+		BuildUrl(&HttpRequest{ Url: "http://localhost/pets", Query: query }))
+	assert.Equal(t, "http://localhost:17779/pets?lang=en&tag=x#top",
+		BuildUrl(&HttpRequest{ Url: "http://localhost:17779/pets?lang=en#top", Query: []HttpQueryParam{ { Name: "tag", Value: "x" } } }))
+	assert.Equal(t, "http://localhost:17779/v1/pets?limit=",
+		BuildUrl(&HttpRequest{ PDP: "http://localhost:17779", Path: "/v1/pets", Query: []HttpQueryParam{ { Name: "limit" } } }))
+}
+
+func TestSplitQuery(t *testing.T) {
+	url, params, ok := SplitQuery("http://localhost/pets?tag=a%26b&tag=c+d&empty=")
+	assert.True(t, ok)
+	assert.Equal(t, "http://localhost/pets", url)
+	assert.Equal(t, []HttpQueryParam{
+		{ Name: "tag", Value: "a&b" },
+		{ Name: "tag", Value: "c d" },
+		{ Name: "empty", Value: "" },
+	}, params)
+
+	url, params, ok = SplitQuery("http://localhost/pets?owner=${{ case[login].Body[id] }}")
+	assert.True(t, ok)
+	assert.Equal(t, []HttpQueryParam{ { Name: "owner", Value: "${{ case[login].Body[id] }}" } }, params)
+
+	// the query strings which are not rebuilt as they are
+	for _, rawUrl := range []string{
+		"http://localhost/pets",
+		"http://localhost/pets?debug",
+		"http://localhost/pets?a=1#top",
+		"http://localhost/?a=%zz",
+		"http://localhost/?q=a%20b",
+		"http://localhost/?a=b=c",
+		"http://localhost/?name=%7E",
+	} {
+		url, params, ok = SplitQuery(rawUrl)
+		assert.False(t, ok)
+		assert.Equal(t, rawUrl, url)
+		assert.Nil(t, params)
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// the relative paths of body-file & the multipart files are resolved from this directory (of the test suite)
+func (r *HttpRequest) SetBaseDir(baseDir string) {
+	r.baseDir = baseDir
+}
+
+func (r *HttpRequest) GetBaseDir() string {
+	return r.baseDir
+}
+
+func (r *HttpRequest) ResolvePath(filePath string) string {
+	if len(r.baseDir) == 0 || filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(r.baseDir, filePath)
+}
+
+func (r *HttpRequest) HasBody() bool {
+	return len(r.Body) > 0 || len(r.BodyFile) > 0 || len(r.Form) > 0 || len(r.Multipart) > 0
+}
+
+func (r *HttpRequest) ValidateBody() error {
+	sources := 0
+	for _, given := range []bool{ len(r.Body) > 0, len(r.BodyFile) > 0, len(r.Form) > 0, len(r.Multipart) > 0 } {
+		if given {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("Only one of body, body-file, form and multipart can be given")
+	}
+	for _, part := range r.Multipart {
+		if len(part.Value) > 0 && len(part.File) > 0 {
+			return fmt.Errorf("Only one of value and file can be given to the part [%s] of multipart", part.Name)
+		}
+	}
+	return nil
+}
+
+func (r *HttpRequest) buildBody() ([]byte, string, error) {
+	if err := r.ValidateBody(); err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case len(r.BodyFile) > 0:
+		body, err := ioutil.ReadFile(r.ResolvePath(r.BodyFile))
+		if err != nil {
+			return nil, "", fmt.Errorf("Cannot read the body file [%s], error: %s", r.BodyFile, err)
+		}
+		return body, "", nil
+	case len(r.Form) > 0:
+		return []byte(EncodeForm(r.Form)), "application/x-www-form-urlencoded", nil
+	case len(r.Multipart) > 0:
+		return r.buildMultipart()
+	}
+	return []byte(r.Body), "", nil
+}
+
+// encodes the fields in their order (url.Values sorts them)
+func EncodeForm(fields []HttpFormField) string {
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		pairs = append(pairs, url.QueryEscape(field.Name) + "=" + url.QueryEscape(field.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func (r *HttpRequest) buildMultipart() ([]byte, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range r.Multipart {
+		header := make(textproto.MIMEHeader)
+		content := []byte(part.Value)
+		if len(part.File) > 0 {
+			data, err := ioutil.ReadFile(r.ResolvePath(part.File))
+			if err != nil {
+				return nil, "", fmt.Errorf("Cannot read the file [%s] of the part [%s], error: %s", part.File, part.Name, err)
+			}
+			content = data
+			filename := part.Filename
+			if len(filename) == 0 {
+				filename = filepath.Base(part.File)
+			}
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(part.Name), escapeQuotes(filename)))
+			contentType := part.ContentType
+			if len(contentType) == 0 {
+				contentType = "application/octet-stream"
+			}
+			header.Set("Content-Type", contentType)
+		} else {
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(part.Name)))
+			if len(part.ContentType) > 0 {
+				header.Set("Content-Type", part.ContentType)
+			}
+		}
+		writer, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,75 @@
+package client
+
+import(
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpRequest_GetRawRequest_Body(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-body")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "pet.json"), []byte(`{ "name": "Kitty" }`), 0644))
+
+	t.Run("Body file relative to the test suite", func(t *testing.T) {
+		req := &HttpRequest{ Method: "POST", Url: "http://localhost/pets", BodyFile: "pet.json" }
+		req.SetBaseDir(dir)
+		lowReq, err := req.GetRawRequest()
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(lowReq.Body)
+		assert.Equal(t, `{ "name": "Kitty" }`, string(body))
+	})
+
+	t.Run("Url-encoded form", func(t *testing.T) {
+		req := &HttpRequest{ Method: "POST", Url: "http://localhost/login", Form: []HttpFormField{
+			{ Name: "user", Value: "admin" },
+			{ Name: "pass", Value: "a&b c" },
+		} }
+		lowReq, err := req.GetRawRequest()
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(lowReq.Body)
+		assert.Equal(t, "user=admin&pass=a%26b+c", string(body))
+		assert.Equal(t, "application/x-www-form-urlencoded", lowReq.Header.Get("Content-Type"))
+	})
+
+	t.Run("Multipart form", func(t *testing.T) {
+		req := &HttpRequest{ Method: "POST", Url: "http://localhost/pets", Multipart: []HttpFormPart{
+			{ Name: "name", Value: "Kitty" },
+			{ Name: "profile", File: "pet.json", ContentType: "application/json" },
+		} }
+		req.SetBaseDir(dir)
+		lowReq, err := req.GetRawRequest()
+		assert.Nil(t, err)
+		mediaType, params, err := mime.ParseMediaType(lowReq.Header.Get("Content-Type"))
+		assert.Nil(t, err)
+		assert.Equal(t, "multipart/form-data", mediaType)
+
+		reader := multipart.NewReader(lowReq.Body, params["boundary"])
+		part, err := reader.NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, "name", part.FormName())
+		value, _ := ioutil.ReadAll(part)
+		assert.Equal(t, "Kitty", string(value))
+
+		part, err = reader.NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, "pet.json", part.FileName())
+		assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+		value, _ = ioutil.ReadAll(part)
+		assert.Equal(t, `{ "name": "Kitty" }`, string(value))
+	})
+
+	t.Run("Invalid bodies", func(t *testing.T) {
+		_, err := (&HttpRequest{ Url: "http://localhost", Body: "{}", Form: []HttpFormField{ { Name: "a" } } }).GetRawRequest()
+		assert.Equal(t, "Only one of body, body-file, form and multipart can be given", err.Error())
+		_, err = (&HttpRequest{ Url: "http://localhost", BodyFile: filepath.Join(dir, "missing.json") }).GetRawRequest()
+		assert.NotNil(t, err)
+		_, err = (&HttpRequest{ Url: "http://localhost", Multipart: []HttpFormPart{ { Name: "photo", Value: "x", File: "pet.png" } } }).GetRawRequest()
+		assert.Equal(t, "Only one of value and file can be given to the part [photo] of multipart", err.Error())
+	})
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	REDIRECTS_FOLLOW string = "follow"
+	REDIRECTS_NONE string = "none"
+)
+
+type HttpTransportOptions struct {
+	Proxy string
+	CACert string
+	ClientCert string
+	ClientKey string
+	InsecureSkipVerify bool
+	Redirects string
+	HTTP2 *bool
+}
+
+// the transport is shared by the requests of an invoker, the connections are reused
+func newHttpTransport(opts *HttpTransportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts == nil {
+		return transport, nil
+	}
+
+	if len(opts.Proxy) > 0 {
+		proxyUrl, err := url.Parse(opts.Proxy)
+		if err != nil || len(proxyUrl.Host) == 0 {
+			return nil, fmt.Errorf("Invalid proxy URL [%s]", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	tlsConfig := &tls.Config{ InsecureSkipVerify: opts.InsecureSkipVerify }
+	if len(opts.CACert) > 0 {
+		pem, err := ioutil.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read the CA bundle [%s], error: %s", opts.CACert, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("There is no PEM certificate in the CA bundle [%s]", opts.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(opts.ClientCert) > 0 {
+		// the private key may be in the certificate file
+		keyFile := opts.ClientKey
+		if len(keyFile) == 0 {
+			keyFile = opts.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot load the client certificate [%s], error: %s", opts.ClientCert, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{ cert }
+	} else if len(opts.ClientKey) > 0 {
+		return nil, fmt.Errorf("The client key [%s] requires a client certificate", opts.ClientKey)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if opts.HTTP2 != nil && !*opts.HTTP2 {
+		// an empty (not nil) map disables HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport, nil
+}
+
+// the redirect policy: "follow" (default, at most 10 redirects), "none" or the maximum number of redirects
+func newRedirectPolicy(redirects string) (func(req *http.Request, via []*http.Request) error, error) {
+	switch(redirects) {
+	case "", REDIRECTS_FOLLOW:
+		return nil, nil
+	case REDIRECTS_NONE:
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}, nil
+	}
+	max, err := strconv.Atoi(redirects)
+	if err != nil || max < 0 {
+		return nil, fmt.Errorf("Invalid redirect policy [%s], must be follow, none or a number", redirects)
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,135 @@
+package client
+
+import(
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpInvoker_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch(r.URL.Path) {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	invoke := func(redirects string) (*HttpResponse, error) {
+		invoker, err := NewHttpInvoker(&HttpInvokerOptions{ Transport: &HttpTransportOptions{ Redirects: redirects } })
+		if err != nil {
+			return nil, err
+		}
+		return invoker.Do(&HttpRequest{ Url: server.URL + "/a" })
+	}
+
+	res, err := invoke(REDIRECTS_FOLLOW)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = invoke(REDIRECTS_NONE)
+	assert.Nil(t, err)
+	assert.Equal(t, 302, res.StatusCode)
+	assert.Equal(t, "/b", res.Header.Get("Location"))
+
+	res, err = invoke("2")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	_, err = invoke("1")
+	assert.NotNil(t, err)
+
+	_, err = invoke("sometimes")
+	assert.Equal(t, "Invalid redirect policy [sometimes], must be follow, none or a number", err.Error())
+}
+
+func TestHttpInvoker_TLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	server.TLS = &tls.Config{ ClientAuth: tls.RequestClientCert }
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "opwire-testa-tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	caPath := filepath.Join(dir, "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{ Type: "CERTIFICATE", Bytes: server.Certificate().Raw }), 0644))
+	certPath, keyPath := writeClientCertificate(t, dir, "testa-client")
+
+	invoke := func(opts *HttpTransportOptions) (*HttpResponse, error) {
+		invoker, err := NewHttpInvoker(&HttpInvokerOptions{ Transport: opts })
+		if err != nil {
+			return nil, err
+		}
+		return invoker.Do(&HttpRequest{ Url: server.URL })
+	}
+
+	t.Run("Unknown authority is refused", func(t *testing.T) {
+		_, err := invoke(nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		res, err := invoke(&HttpTransportOptions{ InsecureSkipVerify: true })
+		assert.Nil(t, err)
+		assert.Equal(t, 200, res.StatusCode)
+	})
+
+	t.Run("Custom CA bundle & client certificate", func(t *testing.T) {
+		res, err := invoke(&HttpTransportOptions{ CACert: caPath, ClientCert: certPath, ClientKey: keyPath })
+		assert.Nil(t, err)
+		assert.Equal(t, "testa-client", string(res.Body))
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := invoke(&HttpTransportOptions{ CACert: keyPath })
+		assert.NotNil(t, err)
+		_, err = invoke(&HttpTransportOptions{ ClientKey: keyPath })
+		assert.NotNil(t, err)
+		_, err = invoke(&HttpTransportOptions{ Proxy: "proxy.local" })
+		assert.Equal(t, "Invalid proxy URL [proxy.local]", err.Error())
+	})
+}
+
+func writeClientCertificate(t *testing.T, dir string, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{ CommonName: name },
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter: time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{ x509.ExtKeyUsageClientAuth },
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certPath := filepath.Join(dir, name + ".pem")
+	keyPath := filepath.Join(dir, name + ".key")
+	assert.Nil(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{ Type: "CERTIFICATE", Bytes: der }), 0644))
+	assert.Nil(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{ Type: "EC PRIVATE KEY", Bytes: keyDer }), 0600))
+	return certPath, keyPath
+}
@@ -0,0 +1,273 @@
+package comparison
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	OP_EQUAL_TO = "equal-to"
+	OP_NOT_EQUAL_TO = "not-equal-to"
+	OP_LT = "lt"
+	OP_LTE = "lte"
+	OP_GT = "gt"
+	OP_GTE = "gte"
+	OP_MEMBER_OF = "member-of"
+	OP_NOT_MEMBER_OF = "not-member-of"
+	OP_MATCH_WITH = "match-with"
+	OP_STARTS_WITH = "starts-with"
+	OP_ENDS_WITH = "ends-with"
+	OP_CONTAINS = "contains"
+	OP_IS_EMPTY = "is-empty"
+	OP_HAS_TYPE = "has-type"
+	OP_HAS_LENGTH = "has-length"
+)
+
+const (
+	TYPE_NULL = "null"
+	TYPE_BOOLEAN = "boolean"
+	TYPE_INTEGER = "integer"
+	TYPE_NUMBER = "number"
+	TYPE_STRING = "string"
+	TYPE_ARRAY = "array"
+	TYPE_OBJECT = "object"
+)
+
+func Evaluate(operator string, rVal, eVal interface{}) (bool, error) {
+	switch(operator) {
+	case OP_EQUAL_TO:
+		return IsEqualTo(rVal, eVal)
+	case OP_NOT_EQUAL_TO:
+		eq, err := IsEqualTo(rVal, eVal)
+		return !eq, err
+	case OP_LT, OP_LTE, OP_GT, OP_GTE:
+		c, err := Compare(rVal, eVal)
+		if err != nil {
+			return false, err
+		}
+		switch(operator) {
+		case OP_LT:
+			return c < 0, nil
+		case OP_LTE:
+			return c <= 0, nil
+		case OP_GT:
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case OP_MEMBER_OF, OP_NOT_MEMBER_OF:
+		list, ok := toList(eVal)
+		if !ok {
+			return false, fmt.Errorf("Operator [%s] requires a list, but got [%v]", operator, eVal)
+		}
+		belongs := BelongsTo(rVal, list)
+		if operator == OP_MEMBER_OF {
+			return belongs, nil
+		}
+		return !belongs, nil
+	case OP_MATCH_WITH:
+		re, err := regexp.Compile(toString(eVal))
+		if err != nil {
+			return false, fmt.Errorf("Invalid regular expression [%v], error: %s", eVal, err)
+		}
+		return rVal != nil && re.MatchString(toString(rVal)), nil
+	case OP_STARTS_WITH:
+		return rVal != nil && strings.HasPrefix(toString(rVal), toString(eVal)), nil
+	case OP_ENDS_WITH:
+		return rVal != nil && strings.HasSuffix(toString(rVal), toString(eVal)), nil
+	case OP_CONTAINS:
+		return Contains(rVal, eVal), nil
+	case OP_IS_EMPTY:
+		expected, ok := eVal.(bool)
+		if !ok {
+			return false, fmt.Errorf("Operator [%s] requires a boolean, but got [%v]", operator, eVal)
+		}
+		return IsEmpty(rVal) == expected, nil
+	case OP_HAS_TYPE:
+		expected := toString(eVal)
+		actual := TypeOf(rVal)
+		if expected == TYPE_NUMBER && actual == TYPE_INTEGER {
+			return true, nil
+		}
+		return actual == expected, nil
+	case OP_HAS_LENGTH:
+		length, ok := LengthOf(rVal)
+		if !ok {
+			return false, fmt.Errorf("Value [%v] has no length", rVal)
+		}
+		return IsEqualTo(length, eVal)
+	}
+	return false, fmt.Errorf("Unsupported operator [%s]", operator)
+}
+
+func Compare(rVal, eVal interface{}) (int, error) {
+	if rVal == nil || eVal == nil {
+		return 0, fmt.Errorf("Cannot compare the values [%v] and [%v]", rVal, eVal)
+	}
+	if rNum, ok := toNumber(rVal); ok {
+		if eNum, ok := toNumber(eVal); ok {
+			return compareFloat(rNum, eNum), nil
+		}
+	}
+	rStr, rIsStr := rVal.(string)
+	eStr, eIsStr := eVal.(string)
+	if rIsStr && eIsStr {
+		if rTime, ok := toTime(rStr); ok {
+			if eTime, ok := toTime(eStr); ok {
+				return compareTime(rTime, eTime), nil
+			}
+		}
+		return strings.Compare(rStr, eStr), nil
+	}
+	return 0, fmt.Errorf("Cannot compare the values [%v] and [%v]", rVal, eVal)
+}
+
+func Contains(rVal, eVal interface{}) bool {
+	if rVal == nil {
+		return false
+	}
+	if list, ok := toList(rVal); ok {
+		return BelongsTo(eVal, list)
+	}
+	v := reflect.ValueOf(rVal)
+	if v.Kind() == reflect.Map {
+		for _, key := range v.MapKeys() {
+			if eq, _ := IsEqualTo(key.Interface(), eVal); eq {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(toString(rVal), toString(eVal))
+}
+
+func IsEmpty(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	if length, ok := LengthOf(val); ok {
+		return length == 0
+	}
+	return false
+}
+
+func LengthOf(val interface{}) (int, bool) {
+	if str, ok := val.(string); ok {
+		return len([]rune(str)), true
+	}
+	v := reflect.ValueOf(val)
+	switch(v.Kind()) {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+func TypeOf(val interface{}) string {
+	if val == nil {
+		return TYPE_NULL
+	}
+	v := reflect.ValueOf(val)
+	switch(v.Kind()) {
+	case reflect.Bool:
+		return TYPE_BOOLEAN
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TYPE_INTEGER
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == float64(int64(f)) {
+			return TYPE_INTEGER
+		}
+		return TYPE_NUMBER
+	case reflect.String:
+		return TYPE_STRING
+	case reflect.Slice, reflect.Array:
+		return TYPE_ARRAY
+	case reflect.Map, reflect.Struct:
+		return TYPE_OBJECT
+	}
+	return v.Kind().String()
+}
+
+func toString(val interface{}) string {
+	if str, ok := val.(string); ok {
+		return str
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+func compareFloat(x, y float64) int {
+	if x < y {
+		return -1
+	}
+	if x > y {
+		return 1
+	}
+	return 0
+}
+
+func compareTime(x, y time.Time) int {
+	if x.Before(y) {
+		return -1
+	}
+	if x.After(y) {
+		return 1
+	}
+	return 0
+}
+
+func toNumber(val interface{}) (float64, bool) {
+	v := reflect.ValueOf(val)
+	switch(v.Kind()) {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+var TIME_LAYOUTS = []string{
+	time.RFC3339Nano,
+	http.TimeFormat,
+	time.RFC1123,
+	time.RFC1123Z,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func toTime(str string) (time.Time, bool) {
+	str = strings.TrimSpace(str)
+	for _, layout := range TIME_LAYOUTS {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toList(val interface{}) ([]interface{}, bool) {
+	if list, ok := val.([]interface{}); ok {
+		return list, true
+	}
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make([]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, true
+}
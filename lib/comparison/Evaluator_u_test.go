@@ -0,0 +1,89 @@
+package comparison
+
+import(
+	"testing"
+	"github.com/opwire/opwire-testa/lib/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Run("Numbers comparison", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_LT, 200, 300)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_LTE, 200, 200.0)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_GT, "10", 9)))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_GTE, 1.5, 2)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_EQUAL_TO, 200, 200.0)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_GTE, "1.0", 1)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_NOT_EQUAL_TO, 404, 200)))
+	})
+
+	t.Run("Strings comparison", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_LT, "apple", "banana")))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_GT, "apple", "banana")))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_NOT_EQUAL_TO, "apple", "banana")))
+	})
+
+	t.Run("Dates comparison", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_GT, "2019-05-01T10:20:30Z", "2019-05-01")))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_LT, "Wed, 01 May 2019 10:20:30 GMT", "2019-05-02")))
+		// compared as dates, not as strings
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_LT, "2019-05-01T10:20:30+07:00", "2019-05-01T05:00:00Z")))
+	})
+
+	t.Run("Membership", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_MEMBER_OF, 201, []interface{}{ 200, 201 })))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_NOT_MEMBER_OF, "201", []interface{}{ 200, 201 })))
+		_, err := Evaluate(OP_MEMBER_OF, 201, 200)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Incomparable values", func(t *testing.T) {
+		_, err := Evaluate(OP_LT, nil, 1)
+		assert.NotNil(t, err)
+		_, err = Evaluate(OP_GT, true, "abc")
+		assert.NotNil(t, err)
+		_, err = Evaluate("approximately", 1, 1)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEvaluate_StringAndTypeOperators(t *testing.T) {
+	t.Run("Patterns, prefixes & suffixes", func(t *testing.T) {
+		uuid := "^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$"
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_MATCH_WITH, "0b9fd5e2-4a1c-4c6e-9d0e-2f6f1d3c8a7b", uuid)))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_MATCH_WITH, "not-a-uuid", uuid)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_MATCH_WITH, 201, "^2\\d\\d$")))
+		_, err := Evaluate(OP_MATCH_WITH, "abc", "[a-")
+		assert.NotNil(t, err)
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_STARTS_WITH, "application/json; charset=utf-8", "application/json")))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_ENDS_WITH, "opwire.org", ".org")))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_ENDS_WITH, nil, "")))
+	})
+
+	t.Run("Contains", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_CONTAINS, "opwire-testa", "test")))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_CONTAINS, []interface{}{ "admin", 2 }, 2)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_CONTAINS, map[string]interface{}{ "id": 1 }, "id")))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_CONTAINS, []interface{}{}, "admin")))
+	})
+
+	t.Run("Emptiness, types & lengths", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_IS_EMPTY, "", true)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_IS_EMPTY, nil, true)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_IS_EMPTY, []interface{}{ 1 }, false)))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_IS_EMPTY, map[string]interface{}{}, false)))
+
+		assert.Equal(t, TYPE_NULL, TypeOf(nil))
+		assert.Equal(t, TYPE_INTEGER, TypeOf(12.0))
+		assert.Equal(t, TYPE_NUMBER, TypeOf(1.5))
+		assert.Equal(t, TYPE_ARRAY, TypeOf([]interface{}{}))
+		assert.Equal(t, TYPE_OBJECT, TypeOf(map[string]interface{}{}))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_HAS_TYPE, 12, TYPE_NUMBER)))
+		assert.False(t, testutils.GetFirstResult_bool(Evaluate(OP_HAS_TYPE, "12", TYPE_NUMBER)))
+
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_HAS_LENGTH, "héllo", 5)))
+		assert.True(t, testutils.GetFirstResult_bool(Evaluate(OP_HAS_LENGTH, []interface{}{ 1, 2 }, 2)))
+		_, err := Evaluate(OP_HAS_LENGTH, 10, 2)
+		assert.NotNil(t, err)
+	})
+}
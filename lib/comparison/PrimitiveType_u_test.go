@@ -17,4 +17,12 @@ func TestIsEqualTo(t *testing.T) {
 		var y float64 = 1024
 		assert.True(t, testutils.GetFirstResult_bool(IsEqualTo(x, y)))
 	})
+
+	t.Run("Numeric strings are not coerced", func(t *testing.T) {
+		assert.False(t, testutils.GetFirstResult_bool(IsEqualTo("007", 7)))
+		assert.False(t, testutils.GetFirstResult_bool(IsEqualTo(" 7 ", 7)))
+		assert.False(t, testutils.GetFirstResult_bool(IsEqualTo("1e3", 1000)))
+		assert.True(t, testutils.GetFirstResult_bool(IsEqualTo("7", 7)))
+		assert.False(t, BelongsTo("007", []interface{}{ 7, 8 }))
+	})
 }
@@ -1,13 +1,20 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"gopkg.in/yaml.v2"
 	"github.com/opwire/opwire-testa/lib/schema"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type LoaderOptions interface {}
 
 type Loader struct {
 	validator *schema.Validator
+	envValidator *schema.Validator
 }
 
 func NewLoader(opts LoaderOptions) (ref *Loader, err error) {
@@ -16,11 +23,548 @@ func NewLoader(opts LoaderOptions) (ref *Loader, err error) {
 	if err != nil {
 		return nil, err
 	}
+	ref.envValidator, err = schema.NewValidator(&schema.ValidatorOptions{ Schema: environmentSchema })
+	if err != nil {
+		return nil, err
+	}
 	return ref, nil
 }
 
+func (l *Loader) Load(configPath string) (*Configuration, error) {
+	if len(configPath) == 0 {
+		configPath = filepath.Join(utils.FindWorkingDir(), utils.DEFAULT_CONFIG_FILENAME)
+		if !utils.IsFile(configPath) {
+			return nil, nil
+		}
+	}
+
+	configPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Configuration{}
+	if err := l.decodeFile(configPath, l.validator, cfg); err != nil {
+		return nil, err
+	}
+
+	// test-dirs & env-dirs are relative to the directory of the configuration file
+	cfg.sourcePath = configPath
+	cfg.TestDirs = utils.Map(cfg.TestDirs, func(dir string, i int) string {
+		return utils.ResolvePath(filepath.Dir(configPath), dir)
+	})
+	cfg.EnvDirs = utils.Map(cfg.EnvDirs, func(dir string, i int) string {
+		return utils.ResolvePath(filepath.Dir(configPath), dir)
+	})
+
+	// so are the certificate files
+	if cfg.Http != nil {
+		for _, file := range []*string{ cfg.Http.CACert, cfg.Http.ClientCert, cfg.Http.ClientKey } {
+			if file != nil && len(*file) > 0 {
+				*file = utils.ResolvePath(filepath.Dir(configPath), *file)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+func (l *Loader) LoadEnvironment(cfg *Configuration, name string) (*Environment, error) {
+	if len(name) == 0 {
+		return nil, nil
+	}
+	if !utils.ENV_NAME_REGEXP.MatchString(name) {
+		return nil, fmt.Errorf("Invalid environment name [%s]", name)
+	}
+
+	// environments declared in the configuration file
+	if cfg != nil && cfg.Environments != nil {
+		if env, ok := cfg.Environments[name]; ok && env != nil {
+			return env, nil
+		}
+	}
+
+	// environments declared in separated files (envs/<name>.yml)
+	envDirs := []string{}
+	if cfg != nil && len(cfg.EnvDirs) > 0 {
+		envDirs = cfg.EnvDirs
+	} else {
+		baseDir := utils.FindWorkingDir()
+		if cfg != nil && len(cfg.sourcePath) > 0 {
+			baseDir = filepath.Dir(cfg.sourcePath)
+		}
+		envDirs = append(envDirs, filepath.Join(baseDir, utils.DEFAULT_ENV_DIRNAME))
+	}
+	for _, envDir := range envDirs {
+		envPath := filepath.Join(envDir, name + ".yml")
+		if !utils.IsFile(envPath) {
+			continue
+		}
+		env := &Environment{}
+		if err := l.decodeFile(envPath, l.envValidator, env); err != nil {
+			return nil, err
+		}
+		return env, nil
+	}
+
+	return nil, fmt.Errorf("Environment [%s] not found", name)
+}
+
+func (l *Loader) decodeFile(filePath string, validator *schema.Validator, target interface{}) error {
+	fs := storage.GetFs()
+	file, err := fs.Open(filePath)
+	if file != nil {
+		defer file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("Cannot open configuration file [%s], error: %s", filePath, err)
+	}
+
+	parser := yaml.NewDecoder(file)
+	parser.SetStrict(true)
+	if err := parser.Decode(target); err != nil {
+		return fmt.Errorf("Cannot parse configuration file [%s], error: %s", filePath, err)
+	}
+
+	// validate the configuration by schema
+	result, err := validator.Validate(target)
+	if err != nil {
+		return err
+	}
+	if result != nil && !result.Valid() {
+		errs := make([]string, len(result.Errors()))
+		for i, arg := range result.Errors() {
+			errs[i] = arg.String()
+		}
+		return utils.CombineErrors(fmt.Sprintf("Invalid configuration file [%s]", filePath), errs)
+	}
+	return nil
+}
+
+type Configuration struct {
+	TestDirs []string `yaml:"test-dirs,omitempty" json:"test-dirs"`
+	InclFiles []string `yaml:"incl-files,omitempty" json:"incl-files"`
+	ExclFiles []string `yaml:"excl-files,omitempty" json:"excl-files"`
+	Tags []string `yaml:"tags,omitempty" json:"tags"`
+	NoColor *bool `yaml:"no-color,omitempty" json:"no-color"`
+	Http *SectionHttp `yaml:"http,omitempty" json:"http"`
+	Snapshot *SectionSnapshot `yaml:"snapshot,omitempty" json:"snapshot"`
+	Environments map[string]*Environment `yaml:"environments,omitempty" json:"environments"`
+	EnvDirs []string `yaml:"env-dirs,omitempty" json:"env-dirs"`
+	sourcePath string
+}
+
+func (c *Configuration) GetSourcePath() string {
+	return c.sourcePath
+}
+
+type SectionHttp struct {
+	PDP *string `yaml:"pdp,omitempty" json:"pdp"`
+	Timeout *string `yaml:"timeout,omitempty" json:"timeout"`
+	Proxy *string `yaml:"proxy,omitempty" json:"proxy"`
+	CACert *string `yaml:"ca-cert,omitempty" json:"ca-cert"`
+	ClientCert *string `yaml:"client-cert,omitempty" json:"client-cert"`
+	ClientKey *string `yaml:"client-key,omitempty" json:"client-key"`
+	InsecureSkipVerify *bool `yaml:"insecure-skip-verify,omitempty" json:"insecure-skip-verify"`
+	Redirects *string `yaml:"redirects,omitempty" json:"redirects"`
+	HTTP2 *bool `yaml:"http2,omitempty" json:"http2"`
+}
+
+type SectionSnapshot struct {
+	ExcludedHeaders []string `yaml:"excluded-headers,omitempty" json:"excluded-headers"`
+	VolatileHeaders []string `yaml:"volatile-headers,omitempty" json:"volatile-headers"`
+	HeaderTotal *bool `yaml:"header-total,omitempty" json:"header-total"`
+	IgnoredPaths []string `yaml:"ignored-paths,omitempty" json:"ignored-paths"`
+	VolatilePaths []string `yaml:"volatile-paths,omitempty" json:"volatile-paths"`
+	BodyAssertions []string `yaml:"body-assertions,omitempty" json:"body-assertions"`
+}
+
+type Environment struct {
+	PDP *string `yaml:"pdp,omitempty" json:"pdp"`
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables"`
+	OSVariables []string `yaml:"os-variables,omitempty" json:"os-variables"`
+}
+
+// the declared variables, overridden by the listed OS environment variables which are set
+func (e *Environment) GetVariables() map[string]string {
+	if e == nil {
+		return nil
+	}
+	if len(e.OSVariables) == 0 {
+		return e.Variables
+	}
+	variables := make(map[string]string, len(e.Variables) + len(e.OSVariables))
+	for name, value := range e.Variables {
+		variables[name] = value
+	}
+	for _, name := range e.OSVariables {
+		if value, ok := os.LookupEnv(name); ok {
+			variables[name] = value
+		}
+	}
+	return variables
+}
+
 const configSchema string = `{
 	"type": "object",
 	"properties": {
-	}
+		"test-dirs": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		},
+		"incl-files": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		},
+		"excl-files": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		},
+		"tags": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string"
+					}
+				}
+			]
+		},
+		"no-color": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "boolean"
+				}
+			]
+		},
+		"http": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"$ref": "#/definitions/SectionHttp"
+				}
+			]
+		},
+		"snapshot": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"$ref": "#/definitions/SectionSnapshot"
+				}
+			]
+		},
+		"environments": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"propertyNames": {
+						"pattern": "^` + utils.ENV_NAME_PATTERN + `$"
+					},
+					"additionalProperties": {
+						"$ref": "#/definitions/Environment"
+					}
+				}
+			]
+		},
+		"env-dirs": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		}
+	},
+	"definitions": {
+		"Environment": ` + environmentDefinition + `,
+		"SectionHttp": {
+			"type": "object",
+			"properties": {
+				"pdp": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^https?://"
+						}
+					]
+				},
+				"timeout": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^` + utils.TIMEOUT_PATTERN + `$"
+						}
+					]
+				},
+				"proxy": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^(https?|socks5)://"
+						}
+					]
+				},
+				"ca-cert": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"client-cert": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"client-key": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"insecure-skip-verify": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"redirects": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^` + utils.REDIRECTS_PATTERN + `$"
+						}
+					]
+				},
+				"http2": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				}
+			},
+			"additionalProperties": false
+		},
+		"SectionSnapshot": {
+			"type": "object",
+			"properties": {
+				"excluded-headers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"volatile-headers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"header-total": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"ignored-paths": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"volatile-paths": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"body-assertions": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"enum": [ "includes", "fields", "is-equal-to" ]
+							}
+						}
+					]
+				}
+			},
+			"additionalProperties": false
+		}
+	},
+	"additionalProperties": false
 }`
+
+const environmentDefinition string = `{
+			"type": "object",
+			"properties": {
+				"pdp": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^https?://"
+						}
+					]
+				},
+				"variables": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"propertyNames": {
+								"pattern": "^` + utils.ENV_VAR_PATTERN + `$"
+							},
+							"additionalProperties": {
+								"type": "string"
+							}
+						}
+					]
+				},
+				"os-variables": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"pattern": "^` + utils.ENV_VAR_PATTERN + `$"
+							}
+						}
+					]
+				}
+			},
+			"additionalProperties": false
+		}`
+
+const environmentSchema string = environmentDefinition
@@ -0,0 +1,178 @@
+package config
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "opwire-testa-config")
+	if err != nil {
+		t.Fatalf("Cannot create temporary directory, error: %s", err)
+	}
+	configPath := filepath.Join(dir, ".opwire-testa.yml")
+	if err := ioutil.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Cannot write configuration file, error: %s", err)
+	}
+	return configPath
+}
+
+func TestLoader_Load(t *testing.T) {
+	loader, err := NewLoader(nil)
+	assert.Nil(t, err)
+
+	t.Run("Valid configuration", func(t *testing.T) {
+		configPath := writeConfigFile(t, `
+test-dirs:
+  - tests
+  - /opt/tests
+excl-files:
+  - tests/demo/*
+tags:
+  - +smoke
+no-color: true
+http:
+  pdp: http://localhost:8888
+  timeout: 5s
+  proxy: http://proxy.local:3128
+  ca-cert: certs/ca.pem
+  client-cert: /etc/testa/client.pem
+  insecure-skip-verify: false
+  redirects: 5
+  http2: false
+snapshot:
+  volatile-paths: [ "**.id" ]
+  body-assertions: [ fields ]
+`)
+		defer os.RemoveAll(filepath.Dir(configPath))
+
+		cfg, err := loader.Load(configPath)
+		assert.Nil(t, err)
+		assert.NotNil(t, cfg)
+		assert.Equal(t, []string{ filepath.Join(filepath.Dir(configPath), "tests"), "/opt/tests" }, cfg.TestDirs)
+		assert.Equal(t, []string{ "tests/demo/*" }, cfg.ExclFiles)
+		assert.Equal(t, []string{ "+smoke" }, cfg.Tags)
+		assert.True(t, *cfg.NoColor)
+		assert.Equal(t, "http://localhost:8888", *cfg.Http.PDP)
+		assert.Equal(t, "5s", *cfg.Http.Timeout)
+		assert.Equal(t, "http://proxy.local:3128", *cfg.Http.Proxy)
+		assert.Equal(t, filepath.Join(filepath.Dir(configPath), "certs/ca.pem"), *cfg.Http.CACert)
+		assert.Equal(t, "/etc/testa/client.pem", *cfg.Http.ClientCert)
+		assert.Nil(t, cfg.Http.ClientKey)
+		assert.Equal(t, "5", *cfg.Http.Redirects)
+		assert.False(t, *cfg.Http.HTTP2)
+		assert.Equal(t, []string{ "**.id" }, cfg.Snapshot.VolatilePaths)
+		assert.Equal(t, []string{ "fields" }, cfg.Snapshot.BodyAssertions)
+		assert.Equal(t, configPath, cfg.GetSourcePath())
+	})
+
+	t.Run("Invalid values are rejected", func(t *testing.T) {
+		configPath := writeConfigFile(t, `
+http:
+  pdp: localhost
+  timeout: 5 seconds
+`)
+		defer os.RemoveAll(filepath.Dir(configPath))
+
+		cfg, err := loader.Load(configPath)
+		assert.Nil(t, cfg)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unknown fields are rejected", func(t *testing.T) {
+		configPath := writeConfigFile(t, `
+test-directories:
+  - tests
+`)
+		defer os.RemoveAll(filepath.Dir(configPath))
+
+		cfg, err := loader.Load(configPath)
+		assert.Nil(t, cfg)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Explicit path must exist", func(t *testing.T) {
+		cfg, err := loader.Load("/not/existing/.opwire-testa.yml")
+		assert.Nil(t, cfg)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLoader_LoadEnvironment(t *testing.T) {
+	loader, err := NewLoader(nil)
+	assert.Nil(t, err)
+
+	configPath := writeConfigFile(t, `
+environments:
+  local:
+    pdp: http://localhost:17779
+    variables:
+      TOKEN: local-token
+`)
+	defer os.RemoveAll(filepath.Dir(configPath))
+
+	envDir := filepath.Join(filepath.Dir(configPath), "envs")
+	assert.Nil(t, os.Mkdir(envDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(envDir, "staging.yml"), []byte(`
+pdp: https://staging.example.com
+variables:
+  TOKEN: staging-token
+  PORT: 8080
+`), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(envDir, "broken.yml"), []byte(`
+variables:
+  INVALID-NAME: value
+`), 0644))
+
+	cfg, err := loader.Load(configPath)
+	assert.Nil(t, err)
+
+	t.Run("Declared in the configuration file", func(t *testing.T) {
+		env, err := loader.LoadEnvironment(cfg, "local")
+		assert.Nil(t, err)
+		assert.Equal(t, "http://localhost:17779", *env.PDP)
+		assert.Equal(t, map[string]string{ "TOKEN": "local-token" }, env.Variables)
+	})
+
+	t.Run("Declared in a separated file", func(t *testing.T) {
+		env, err := loader.LoadEnvironment(cfg, "staging")
+		assert.Nil(t, err)
+		assert.Equal(t, "https://staging.example.com", *env.PDP)
+		assert.Equal(t, map[string]string{ "TOKEN": "staging-token", "PORT": "8080" }, env.Variables)
+	})
+
+	t.Run("Invalid variable names are rejected", func(t *testing.T) {
+		env, err := loader.LoadEnvironment(cfg, "broken")
+		assert.Nil(t, env)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unknown environment", func(t *testing.T) {
+		env, err := loader.LoadEnvironment(cfg, "production")
+		assert.Nil(t, env)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Names must not escape the environment directories", func(t *testing.T) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(filepath.Dir(configPath), "outside.yml"), []byte(`
+pdp: https://outside.example.com
+`), 0644))
+		env, err := loader.LoadEnvironment(cfg, "../outside")
+		assert.Nil(t, env)
+		assert.Equal(t, "Invalid environment name [../outside]", err.Error())
+	})
+
+	t.Run("Listed OS environment variables", func(t *testing.T) {
+		os.Setenv("OPWIRE_TESTA_TOKEN", "os-token")
+		defer os.Unsetenv("OPWIRE_TESTA_TOKEN")
+		env := &Environment{
+			Variables: map[string]string{ "OPWIRE_TESTA_TOKEN": "declared-token", "PORT": "8080" },
+			OSVariables: []string{ "OPWIRE_TESTA_TOKEN", "OPWIRE_TESTA_UNSET" },
+		}
+		assert.Equal(t, map[string]string{ "OPWIRE_TESTA_TOKEN": "os-token", "PORT": "8080" }, env.GetVariables())
+		assert.Equal(t, "declared-token", env.Variables["OPWIRE_TESTA_TOKEN"])
+	})
+}
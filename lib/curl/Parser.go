@@ -0,0 +1,467 @@
+package curl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const MULTIPART_BOUNDARY = "opwire-testa-form-boundary"
+
+// the request of a curl command and the options which are not the parts of the request
+type Command struct {
+	Request *client.HttpRequest
+	Insecure bool
+	FollowRedirects bool
+	Warnings []string
+}
+
+type option struct {
+	name string
+	hasArg bool
+}
+
+var shortOptions = map[rune]option{
+	'X': { "request", true },
+	'H': { "header", true },
+	'd': { "data", true },
+	'F': { "form", true },
+	'u': { "user", true },
+	'b': { "cookie", true },
+	'A': { "user-agent", true },
+	'e': { "referer", true },
+	'm': { "max-time", true },
+	'G': { "get", false },
+	'I': { "head", false },
+	'k': { "insecure", false },
+	'L': { "location", false },
+	'o': { "output", true },
+	'w': { "write-out", true },
+	'x': { "proxy", true },
+	'E': { "cert", true },
+	'c': { "cookie-jar", true },
+	'D': { "dump-header", true },
+	'r': { "range", true },
+	's': { "silent", false },
+	'S': { "show-error", false },
+	'v': { "verbose", false },
+	'i': { "include", false },
+	'f': { "fail", false },
+	'g': { "globoff", false },
+	'N': { "no-buffer", false },
+	'O': { "remote-name", false },
+	'#': { "progress-bar", false },
+	'0': { "http1.0", false },
+}
+
+var longOptions = map[string]bool{
+	"request": true, "url": true, "header": true, "data": true, "data-ascii": true, "data-raw": true,
+	"data-binary": true, "data-urlencode": true, "json": true, "form": true, "form-string": true,
+	"user": true, "cookie": true, "user-agent": true, "referer": true, "max-time": true,
+	"oauth2-bearer": true, "url-query": true, "get": false, "head": false, "insecure": false,
+	"compressed": false, "location": false,
+	// the options which do not change the request
+	"output": true, "write-out": true, "proxy": true, "cert": true, "key": true, "cacert": true,
+	"capath": true, "cookie-jar": true, "dump-header": true, "range": true, "connect-timeout": true,
+	"retry": true, "retry-delay": true, "retry-max-time": true, "max-redirs": true, "limit-rate": true,
+	"resolve": true, "interface": true,
+	"silent": false, "show-error": false, "verbose": false, "include": false, "fail": false,
+	"globoff": false, "no-buffer": false, "remote-name": false, "progress-bar": false,
+	"http1.0": false, "http1.1": false, "http2": false, "http2-prior-knowledge": false,
+	"path-as-is": false, "no-keepalive": false, "tlsv1.2": false, "tlsv1.3": false,
+	"ssl-no-revoke": false, "retry-connrefused": false,
+}
+
+// parses a curl command line (as copied by "Copy as cURL" of the browsers)
+func Parse(cmdline string) (*Command, error) {
+	args, err := Split(cmdline)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid curl command, error: %s", err)
+	}
+	return ParseArgs(args)
+}
+
+func ParseArgs(args []string) (*Command, error) {
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl") || args[0] == "curl.exe") {
+		args = args[1:]
+	}
+	p := &parser{ cmd: &Command{ Request: &client.HttpRequest{} } }
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "--") && len(arg) > 2:
+			name := arg[2:]
+			hasArg, ok := longOptions[name]
+			if !ok {
+				// the unknown options are taken as flags, their values (if any) cannot be told apart from the URL
+				p.ignore(arg)
+				continue
+			}
+			value := ""
+			if hasArg {
+				if i + 1 >= len(args) {
+					return nil, fmt.Errorf("The curl option [%s] requires a value", arg)
+				}
+				i++
+				value = args[i]
+			}
+			if err := p.apply(name, value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// the short options may be combined (e.g. -sSL, -XPOST)
+			letters := []rune(arg[1:])
+			for j := 0; j < len(letters); j++ {
+				opt, ok := shortOptions[letters[j]]
+				if !ok {
+					p.ignore("-" + string(letters[j]))
+					continue
+				}
+				value := ""
+				if opt.hasArg {
+					if j + 1 < len(letters) {
+						value = string(letters[j + 1:])
+					} else if i + 1 < len(args) {
+						i++
+						value = args[i]
+					} else {
+						return nil, fmt.Errorf("The curl option [-%c] requires a value", letters[j])
+					}
+					j = len(letters)
+				}
+				if err := p.apply(opt.name, value); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if err := p.apply("url", arg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.build()
+}
+
+type parser struct {
+	cmd *Command
+	method string
+	url string
+	headers []client.HttpHeader
+	data []string
+	form []formField
+	query []string
+	get bool
+	head bool
+	json bool
+	compressed bool
+}
+
+type formField struct {
+	name string
+	value string
+	file string
+	contentType string
+}
+
+func (p *parser) apply(name string, value string) error {
+	req := p.cmd.Request
+	switch(name) {
+	case "request":
+		p.method = value
+	case "url":
+		if len(p.url) > 0 {
+			return fmt.Errorf("Only one URL is supported, [%s] and [%s] are given", p.url, value)
+		}
+		p.url = value
+	case "header":
+		pos := strings.IndexAny(value, ":;")
+		if pos <= 0 {
+			return fmt.Errorf("Invalid header [%s]", value)
+		}
+		if value[pos] == ':' && len(strings.TrimSpace(value[pos + 1:])) == 0 {
+			// "Name:" removes an internal header of curl
+			return nil
+		}
+		p.headers = append(p.headers, client.HttpHeader{
+			Name: strings.TrimSpace(value[:pos]),
+			Value: strings.TrimSpace(value[pos + 1:]),
+		})
+	case "data", "data-ascii":
+		if strings.HasPrefix(value, "@") {
+			content, err := readFile(value[1:])
+			if err != nil {
+				return err
+			}
+			value = strings.NewReplacer("\r", "", "\n", "").Replace(string(content))
+		}
+		p.data = append(p.data, value)
+	case "data-binary":
+		if strings.HasPrefix(value, "@") {
+			content, err := readFile(value[1:])
+			if err != nil {
+				return err
+			}
+			value = string(content)
+		}
+		p.data = append(p.data, value)
+	case "data-raw":
+		p.data = append(p.data, value)
+	case "data-urlencode":
+		encoded, err := urlencode(value)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, encoded)
+	case "json":
+		if strings.HasPrefix(value, "@") {
+			content, err := readFile(value[1:])
+			if err != nil {
+				return err
+			}
+			value = string(content)
+		}
+		p.data = append(p.data, value)
+		p.json = true
+	case "form", "form-string":
+		field, err := parseFormField(value, name == "form-string")
+		if err != nil {
+			return err
+		}
+		p.form = append(p.form, field)
+	case "user":
+		if !strings.Contains(value, ":") {
+			value += ":"
+		}
+		p.headers = append(p.headers, client.HttpHeader{ Name: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(value)) })
+	case "oauth2-bearer":
+		p.headers = append(p.headers, client.HttpHeader{ Name: "Authorization", Value: "Bearer " + value })
+	case "cookie":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("The cookie files are not supported [%s]", value)
+		}
+		p.headers = append(p.headers, client.HttpHeader{ Name: "Cookie", Value: value })
+	case "user-agent":
+		p.headers = append(p.headers, client.HttpHeader{ Name: "User-Agent", Value: value })
+	case "referer":
+		p.headers = append(p.headers, client.HttpHeader{ Name: "Referer", Value: value })
+	case "max-time":
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("Invalid max-time [%s]", value)
+		}
+		timeout := time.Duration(seconds * float64(time.Second))
+		if timeout % time.Second == 0 {
+			req.Timeout = utils.RefOfString(fmt.Sprintf("%ds", timeout / time.Second))
+		} else {
+			req.Timeout = utils.RefOfString(fmt.Sprintf("%dms", timeout / time.Millisecond))
+		}
+	case "url-query":
+		encoded, err := urlencode(value)
+		if err != nil {
+			return err
+		}
+		p.query = append(p.query, encoded)
+	case "get":
+		p.get = true
+	case "head":
+		p.head = true
+	case "insecure":
+		p.cmd.Insecure = true
+	case "compressed":
+		p.compressed = true
+	case "location":
+		p.cmd.FollowRedirects = true
+	}
+	return nil
+}
+
+func (p *parser) build() (*Command, error) {
+	req := p.cmd.Request
+	if len(p.url) == 0 {
+		return nil, fmt.Errorf("The URL of the curl command is missing")
+	}
+	if !strings.Contains(p.url, "://") {
+		p.url = "http://" + p.url
+	}
+	if len(p.data) > 0 && len(p.form) > 0 {
+		return nil, fmt.Errorf("The data (-d) and the form (-F) options must not be mixed")
+	}
+
+	req.Method = "GET"
+	query := p.query
+	switch {
+	case p.head:
+		req.Method = "HEAD"
+	case p.get:
+		query = append(query, p.data...)
+	case len(p.data) > 0:
+		req.Method = "POST"
+		req.Body = strings.Join(p.data, "&")
+		if p.json {
+			p.defaultHeader("Content-Type", "application/json")
+			p.defaultHeader("Accept", "application/json")
+		}
+		p.defaultHeader("Content-Type", "application/x-www-form-urlencoded")
+	case len(p.form) > 0:
+		req.Method = "POST"
+		body, err := buildMultipart(p.form)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+		p.defaultHeader("Content-Type", "multipart/form-data; boundary=" + MULTIPART_BOUNDARY)
+	}
+	if len(p.method) > 0 {
+		req.Method = p.method
+	}
+
+	req.Url = p.url
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(req.Url, "?") {
+			separator = "&"
+		}
+		req.Url += separator + strings.Join(query, "&")
+	}
+
+	for _, header := range p.headers {
+		// the HTTP client decompresses the responses itself
+		if p.compressed && strings.EqualFold(header.Name, "Accept-Encoding") {
+			continue
+		}
+		req.Headers = append(req.Headers, header)
+	}
+	return p.cmd, nil
+}
+
+func (p *parser) ignore(option string) {
+	p.cmd.Warnings = append(p.cmd.Warnings, fmt.Sprintf("Unsupported curl option [%s] is ignored", option))
+}
+
+func (p *parser) defaultHeader(name string, value string) {
+	for _, header := range p.headers {
+		if strings.EqualFold(header.Name, name) {
+			return
+		}
+	}
+	p.headers = append(p.headers, client.HttpHeader{ Name: name, Value: value })
+}
+
+// the forms of --data-urlencode: "content", "=content", "name=content", "@file", "name@file"
+func urlencode(value string) (string, error) {
+	eq := strings.Index(value, "=")
+	at := strings.Index(value, "@")
+	switch {
+	case eq >= 0 && (at < 0 || eq < at):
+		if eq == 0 {
+			return url.QueryEscape(value[1:]), nil
+		}
+		return value[:eq] + "=" + url.QueryEscape(value[eq + 1:]), nil
+	case at >= 0:
+		content, err := readFile(value[at + 1:])
+		if err != nil {
+			return "", err
+		}
+		if at == 0 {
+			return url.QueryEscape(string(content)), nil
+		}
+		return value[:at] + "=" + url.QueryEscape(string(content)), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+func parseFormField(value string, literal bool) (formField, error) {
+	pos := strings.Index(value, "=")
+	if pos <= 0 {
+		return formField{}, fmt.Errorf("Invalid form field [%s]", value)
+	}
+	field := formField{ name: value[:pos], value: value[pos + 1:] }
+	if literal {
+		return field, nil
+	}
+	if strings.HasPrefix(field.value, "@") || strings.HasPrefix(field.value, "<") {
+		parts := strings.Split(field.value[1:], ";")
+		filePath := parts[0]
+		for _, part := range parts[1:] {
+			if strings.HasPrefix(part, "type=") {
+				field.contentType = part[5:]
+			}
+		}
+		content, err := readFile(filePath)
+		if err != nil {
+			return field, err
+		}
+		field.value = string(content)
+		if strings.HasPrefix(value[pos + 1:], "@") {
+			field.file = filepath.Base(filePath)
+			for _, part := range parts[1:] {
+				if strings.HasPrefix(part, "filename=") {
+					field.file = part[9:]
+				}
+			}
+		}
+		return field, nil
+	}
+	if pos := strings.Index(field.value, ";type="); pos >= 0 {
+		field.contentType = field.value[pos + 6:]
+		field.value = field.value[:pos]
+	}
+	return field, nil
+}
+
+func buildMultipart(fields []formField) (string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(MULTIPART_BOUNDARY); err != nil {
+		return "", err
+	}
+	for _, field := range fields {
+		h := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(field.name))
+		if len(field.file) > 0 {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(field.file))
+			if len(field.contentType) == 0 {
+				field.contentType = "application/octet-stream"
+			}
+		}
+		h.Set("Content-Disposition", disposition)
+		if len(field.contentType) > 0 {
+			h.Set("Content-Type", field.contentType)
+		}
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return "", err
+		}
+		if _, err := part.Write([]byte(field.value)); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+func readFile(filePath string) ([]byte, error) {
+	file, err := storage.GetFs().Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the file [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
@@ -0,0 +1,103 @@
+package curl
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Copy as cURL of a browser", func(t *testing.T) {
+		cmd, err := Parse(`curl 'https://api.example.com/v1/pets?limit=2' \
+  -H 'accept: application/json' \
+  -H 'accept-encoding: gzip, deflate, br' \
+  -H 'content-type: application/json' \
+  -b 'session=abc; theme=dark' \
+  --data-raw '{"name":"Kitty"}' \
+  --compressed -k`)
+		assert.Nil(t, err)
+		assert.True(t, cmd.Insecure)
+		req := cmd.Request
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "https://api.example.com/v1/pets?limit=2", req.Url)
+		assert.Equal(t, []client.HttpHeader{
+			{ Name: "accept", Value: "application/json" },
+			{ Name: "content-type", Value: "application/json" },
+			{ Name: "Cookie", Value: "session=abc; theme=dark" },
+		}, req.Headers)
+		assert.Equal(t, `{"name":"Kitty"}`, req.Body)
+	})
+
+	t.Run("Combined short options, user & timeout", func(t *testing.T) {
+		cmd, err := Parse(`curl -sSL -XPUT -u admin:secret -m 2.5 localhost:8080/items/1 -d a=1 -d b=2`)
+		assert.Nil(t, err)
+		assert.True(t, cmd.FollowRedirects)
+		req := cmd.Request
+		assert.Equal(t, "PUT", req.Method)
+		assert.Equal(t, "http://localhost:8080/items/1", req.Url)
+		assert.Equal(t, "a=1&b=2", req.Body)
+		assert.Equal(t, "2500ms", *req.Timeout)
+		assert.Equal(t, []client.HttpHeader{
+			{ Name: "Authorization", Value: "Basic YWRtaW46c2VjcmV0" },
+			{ Name: "Content-Type", Value: "application/x-www-form-urlencoded" },
+		}, req.Headers)
+	})
+
+	t.Run("Data as query parameters", func(t *testing.T) {
+		cmd, err := Parse(`curl -G http://localhost/search?lang=en --data-urlencode 'q=hello world' --url-query page=2`)
+		assert.Nil(t, err)
+		assert.Equal(t, "GET", cmd.Request.Method)
+		assert.Equal(t, "http://localhost/search?lang=en&page=2&q=hello+world", cmd.Request.Url)
+		assert.Equal(t, "", cmd.Request.Body)
+	})
+
+	t.Run("Files & multipart forms", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "opwire-testa-curl")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		filePath := filepath.Join(dir, "pet.json")
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte("{\n  \"name\": \"Kitty\"\n}\n"), 0644))
+
+		cmd, err := Parse(`curl http://localhost/pets --data-binary @` + filePath)
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"name\": \"Kitty\"\n}\n", cmd.Request.Body)
+
+		cmd, err = Parse(`curl http://localhost/pets -d @` + filePath)
+		assert.Nil(t, err)
+		assert.Equal(t, `{  "name": "Kitty"}`, cmd.Request.Body)
+
+		cmd, err = Parse(`curl http://localhost/pets -F 'name=Kitty' -F 'photo=@` + filePath + `;type=application/json'`)
+		assert.Nil(t, err)
+		assert.Equal(t, "POST", cmd.Request.Method)
+		assert.Equal(t, "multipart/form-data; boundary=" + MULTIPART_BOUNDARY, cmd.Request.Headers[0].Value)
+		assert.True(t, strings.Contains(cmd.Request.Body, "Content-Disposition: form-data; name=\"name\"\r\n\r\nKitty\r\n"))
+		assert.True(t, strings.Contains(cmd.Request.Body, "Content-Disposition: form-data; name=\"photo\"; filename=\"pet.json\"\r\nContent-Type: application/json"))
+	})
+
+	t.Run("Methods are kept as written", func(t *testing.T) {
+		cmd, err := Parse(`curl -X propfind http://localhost/files`)
+		assert.Nil(t, err)
+		assert.Equal(t, "propfind", cmd.Request.Method)
+	})
+
+	t.Run("Unsupported options are ignored", func(t *testing.T) {
+		cmd, err := Parse(`curl --unknown -sZ http://localhost`)
+		assert.Nil(t, err)
+		assert.Equal(t, "http://localhost", cmd.Request.Url)
+		assert.Equal(t, []string{
+			"Unsupported curl option [--unknown] is ignored",
+			"Unsupported curl option [-Z] is ignored",
+		}, cmd.Warnings)
+	})
+
+	t.Run("Invalid commands", func(t *testing.T) {
+		_, err := Parse(`curl -H 'Accept: */*'`)
+		assert.Equal(t, "The URL of the curl command is missing", err.Error())
+		_, err = Parse(`curl http://localhost -d a=1 -F b=2`)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+package curl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splits a command line into words as a POSIX shell does (quotes, escapes, $'...' & line continuations)
+func Split(cmdline string) ([]string, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord := false
+	runes := []rune(cmdline)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\':
+			if i + 1 >= len(runes) {
+				return nil, fmt.Errorf("unexpected end of the command after [\\]")
+			}
+			i++
+			if runes[i] == '\n' {
+				continue
+			}
+			if runes[i] == '\r' && i + 1 < len(runes) && runes[i + 1] == '\n' {
+				i++
+				continue
+			}
+			word.WriteRune(runes[i])
+			inWord = true
+		case c == '\'':
+			end := indexRune(runes, i + 1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(string(runes[i + 1:end]))
+			inWord = true
+			i = end
+		case c == '$' && i + 1 < len(runes) && runes[i + 1] == '\'':
+			end, text, err := unquoteANSI(runes, i + 2)
+			if err != nil {
+				return nil, err
+			}
+			word.WriteString(text)
+			inWord = true
+			i = end
+		case c == '"':
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j + 1 < len(runes) && strings.ContainsRune("\\\"$`\n", runes[j + 1]) {
+					j++
+					if runes[j] != '\n' {
+						word.WriteRune(runes[j])
+					}
+					continue
+				}
+				word.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+			i = j
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// the ANSI-C quoting of bash, as used by the "Copy as cURL" of the browsers
+func unquoteANSI(runes []rune, from int) (int, string, error) {
+	var text strings.Builder
+	for i := from; i < len(runes); i++ {
+		c := runes[i]
+		if c == '\'' {
+			return i, text.String(), nil
+		}
+		if c != '\\' || i + 1 >= len(runes) {
+			text.WriteRune(c)
+			continue
+		}
+		i++
+		switch runes[i] {
+		case 'n':
+			text.WriteRune('\n')
+		case 't':
+			text.WriteRune('\t')
+		case 'r':
+			text.WriteRune('\r')
+		case 'a':
+			text.WriteRune('\a')
+		case 'b':
+			text.WriteRune('\b')
+		case 'e', 'E':
+			text.WriteRune(0x1b)
+		case 'f':
+			text.WriteRune('\f')
+		case 'v':
+			text.WriteRune('\v')
+		case 'x', 'u', 'U':
+			size := map[rune]int{ 'x': 2, 'u': 4, 'U': 8 }[runes[i]]
+			end := i + 1
+			for end < len(runes) && end <= i + size && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+				end++
+			}
+			code, err := strconv.ParseUint(string(runes[i + 1:end]), 16, 32)
+			if err != nil {
+				return 0, "", fmt.Errorf("invalid escape sequence [\\%s]", string(runes[i:end]))
+			}
+			if runes[i] == 'x' {
+				text.WriteByte(byte(code))
+			} else {
+				text.WriteRune(rune(code))
+			}
+			i = end - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := i
+			for end < len(runes) && end < i + 3 && runes[end] >= '0' && runes[end] <= '7' {
+				end++
+			}
+			code, _ := strconv.ParseUint(string(runes[i:end]), 8, 8)
+			text.WriteByte(byte(code))
+			i = end - 1
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated $'...' quote")
+}
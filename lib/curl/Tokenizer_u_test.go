@@ -0,0 +1,35 @@
+package curl
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	t.Run("Quotes, escapes & line continuations", func(t *testing.T) {
+		words, err := Split("curl 'http://localhost/a b' \\\n  -H \"X-Name: \\\"opwire\\\" \\$HOME\" --data-raw $'{\"line\":\"1\\\\n2\\'s\"}' plain\\ word")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"curl",
+			"http://localhost/a b",
+			"-H",
+			`X-Name: "opwire" $HOME`,
+			"--data-raw",
+			`{"line":"1\n2's"}`,
+			"plain word",
+		}, words)
+	})
+
+	t.Run("ANSI-C escape sequences", func(t *testing.T) {
+		words, err := Split(`$'a\tb\x41\u00e9\101'`)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ "a\tbAéA" }, words)
+	})
+
+	t.Run("Unterminated quotes", func(t *testing.T) {
+		_, err := Split(`curl 'http://localhost`)
+		assert.NotNil(t, err)
+		_, err = Split(`curl "http://localhost`)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,222 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"github.com/opwire/opwire-testa/lib/schema"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type SchemaSource struct {
+	Path string
+	Inline interface{}
+}
+
+func (s *SchemaSource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		s.Path = path
+		return nil
+	}
+	var inline map[interface{}]interface{}
+	if err := unmarshal(&inline); err != nil {
+		return err
+	}
+	s.Inline = utils.NormalizeMaps(inline)
+	return nil
+}
+
+func (s SchemaSource) MarshalYAML() (interface{}, error) {
+	if s.Inline != nil {
+		return s.Inline, nil
+	}
+	return s.Path, nil
+}
+
+func (s SchemaSource) MarshalJSON() ([]byte, error) {
+	if s.Inline != nil {
+		return json.Marshal(s.Inline)
+	}
+	return json.Marshal(s.Path)
+}
+
+type schemaRegistry struct {
+	validators map[string]*schema.Validator
+	inlines map[*SchemaSource]*schema.Validator
+	mutex sync.Mutex
+}
+
+// the schema files are cached by their paths, the inline schemas by their testcases
+func (r *schemaRegistry) getValidator(source *SchemaSource, sourcePath string) (*schema.Validator, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if source.Inline != nil {
+		if validator, ok := r.inlines[source]; ok {
+			return validator, nil
+		}
+		validator, err := compileSchema(source.Inline, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		if r.inlines == nil {
+			r.inlines = make(map[*SchemaSource]*schema.Validator)
+		}
+		r.inlines[source] = validator
+		return validator, nil
+	}
+
+	schemaPath := source.Path
+	if !filepath.IsAbs(schemaPath) && len(sourcePath) > 0 {
+		schemaPath = filepath.Join(filepath.Dir(sourcePath), schemaPath)
+	}
+	if validator, ok := r.validators[schemaPath]; ok {
+		return validator, nil
+	}
+
+	schemaObject, err := loadSchemaFile(schemaPath, source.Path)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := compileSchema(schemaObject, schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid schema file [%s], error: %s", source.Path, err)
+	}
+
+	if r.validators == nil {
+		r.validators = make(map[string]*schema.Validator)
+	}
+	r.validators[schemaPath] = validator
+	return validator, nil
+}
+
+// the relative references to the other files (e.g. "address.json#/definitions/city") are resolved from the base path
+func compileSchema(schemaObject interface{}, basePath string) (*schema.Validator, error) {
+	if len(basePath) == 0 {
+		return schema.NewValidator(&schema.ValidatorOptions{ SchemaObject: schemaObject })
+	}
+	schemaURL := toFileURL(basePath)
+	references := map[string]interface{}{ schemaURL: schemaObject }
+	if err := collectReferences(schemaObject, basePath, references); err != nil {
+		return nil, err
+	}
+	if len(references) == 1 {
+		return schema.NewValidator(&schema.ValidatorOptions{ SchemaObject: schemaObject })
+	}
+	return schema.NewValidator(&schema.ValidatorOptions{ SchemaURL: schemaURL, References: references })
+}
+
+func collectReferences(node interface{}, basePath string, references map[string]interface{}) error {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			ref, ok := item.(string)
+			if key != "$ref" || !ok {
+				if err := collectReferences(item, basePath, references); err != nil {
+					return err
+				}
+				continue
+			}
+			refPath := strings.SplitN(ref, "#", 2)[0]
+			if len(refPath) == 0 || strings.Contains(refPath, "://") {
+				continue
+			}
+			refPath = filepath.FromSlash(refPath)
+			if !filepath.IsAbs(refPath) {
+				refPath = filepath.Join(filepath.Dir(basePath), refPath)
+			}
+			refURL := toFileURL(refPath)
+			if _, ok := references[refURL]; ok {
+				continue
+			}
+			schemaObject, err := loadSchemaFile(refPath, ref)
+			if err != nil {
+				return err
+			}
+			references[refURL] = schemaObject
+			if err := collectReferences(schemaObject, refPath, references); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := collectReferences(item, basePath, references); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func loadSchemaFile(schemaPath string, label string) (interface{}, error) {
+	file, err := storage.GetFs().Open(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the schema file [%s], error: %s", label, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the schema file [%s], error: %s", label, err)
+	}
+
+	// the schema file may be written in JSON or YAML
+	var schemaObject interface{}
+	if err := utils.Unmarshal(utils.BODY_FORMAT_YAML, content, &schemaObject); err != nil {
+		return nil, fmt.Errorf("Invalid schema file [%s], error: %s", label, err)
+	}
+	return utils.NormalizeMaps(schemaObject), nil
+}
+
+func toFileURL(filePath string) string {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
+	return "file://" + filepath.ToSlash(filePath)
+}
+
+func (e *SpecHandler) verifyBodySchema(testcase *TestCase, _eb *MeasureBody, body []byte, errors map[string]error) {
+	validator, err := e.schemas.getValidator(_eb.MatchesSchema, testcase.GetSourcePath())
+	if err != nil {
+		errors["Body/MatchesSchema"] = err
+		return
+	}
+
+	format := utils.BODY_FORMAT_JSON
+	if _eb.HasFormat != nil && *_eb.HasFormat == utils.BODY_FORMAT_YAML {
+		format = utils.BODY_FORMAT_YAML
+	}
+	var receivedObj interface{}
+	if err := utils.Unmarshal(format, body, &receivedObj); err != nil {
+		errors["Body/MatchesSchema"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+		return
+	}
+
+	// a null body is validated as well (e.g. against "type: [ object, null ]")
+	var document interface{} = json.RawMessage("null")
+	if receivedObj != nil {
+		document = utils.NormalizeMaps(receivedObj)
+	}
+	result, err := validator.Validate(document)
+	if err != nil {
+		errors["Body/MatchesSchema"] = err
+		return
+	}
+	if result == nil || result.Valid() {
+		return
+	}
+
+	// each violation is reported as an entry
+	for _, violation := range result.Errors() {
+		field := strings.TrimPrefix(violation.Field(), "(root).")
+		key := "Body/MatchesSchema/" + field
+		for i := 2; errors[key] != nil; i++ {
+			key = fmt.Sprintf("Body/MatchesSchema/%s#%d", field, i)
+		}
+		errors[key] = fmt.Errorf("%s", schema.DescribeError(violation))
+	}
+}
@@ -0,0 +1,117 @@
+package engine
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"gopkg.in/yaml.v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecHandler_verifyBodySchema(t *testing.T) {
+	e := &SpecHandler{}
+	body := []byte(`{ "items": [ { "id": "u1", "age": 30 }, { "id": 2, "age": 17 } ] }`)
+
+	t.Run("Inline schema", func(t *testing.T) {
+		_eb := &MeasureBody{}
+		assert.Nil(t, yaml.Unmarshal([]byte(`
+matches-schema:
+  type: object
+  required: [ items, total ]
+  properties:
+    items:
+      type: array
+      items:
+        properties:
+          id: { type: string }
+          age: { type: integer, minimum: 18 }
+`), _eb))
+		errors := make(map[string]error)
+		e.verifyBodySchema(&TestCase{}, _eb, body, errors)
+		assert.Equal(t, 3, len(errors))
+		assert.NotNil(t, errors["Body/MatchesSchema/(root)"])
+		assert.NotNil(t, errors["Body/MatchesSchema/items.1.id"])
+		assert.Equal(t, "Must be greater than or equal to 18", errors["Body/MatchesSchema/items.1.age"].Error())
+	})
+
+	t.Run("Schema file relative to the test suite", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "opwire-testa-schema")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "schemas", "items.json"), []byte(`{ "type": "object", "required": [ "items" ] }`), 0644))
+
+		suite := &TestSuite{ TestCases: []*TestCase{ {} } }
+		suite.SetSourcePath(filepath.Join(dir, "users.yml"))
+
+		errors := make(map[string]error)
+		e.verifyBodySchema(suite.TestCases[0], &MeasureBody{ MatchesSchema: &SchemaSource{ Path: "schemas/items.json" } }, body, errors)
+		assert.Equal(t, 0, len(errors))
+
+		e.verifyBodySchema(suite.TestCases[0], &MeasureBody{ MatchesSchema: &SchemaSource{ Path: "schemas/none.json" } }, body, errors)
+		assert.NotNil(t, errors["Body/MatchesSchema"])
+	})
+
+	t.Run("Inline schema is compiled once per testcase", func(t *testing.T) {
+		source := &SchemaSource{ Inline: map[string]interface{}{ "type": "object" } }
+		first, err := e.schemas.getValidator(source, "")
+		assert.Nil(t, err)
+		second, err := e.schemas.getValidator(source, "")
+		assert.Nil(t, err)
+		assert.True(t, first == second)
+	})
+
+	t.Run("Null body is validated", func(t *testing.T) {
+		errors := make(map[string]error)
+		nullable := &SchemaSource{ Inline: map[string]interface{}{ "type": []interface{}{ "object", "null" } } }
+		e.verifyBodySchema(&TestCase{}, &MeasureBody{ MatchesSchema: nullable }, []byte(`null`), errors)
+		assert.Equal(t, 0, len(errors))
+
+		object := &SchemaSource{ Inline: map[string]interface{}{ "type": "object" } }
+		e.verifyBodySchema(&TestCase{}, &MeasureBody{ MatchesSchema: object }, []byte(`null`), errors)
+		assert.Equal(t, "Invalid type. Expected: object, given: null", errors["Body/MatchesSchema/(root)"].Error())
+	})
+
+	t.Run("Relative references to the sibling files", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "opwire-testa-schema")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "schemas", "user.yml"), []byte(`
+type: object
+properties:
+  address: { $ref: 'address.json#/definitions/address' }
+`), 0644))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "schemas", "address.json"), []byte(`{
+  "definitions": {
+    "address": { "type": "object", "properties": { "city": { "type": "string" } } }
+  }
+}`), 0644))
+
+		suite := &TestSuite{ TestCases: []*TestCase{ {} } }
+		suite.SetSourcePath(filepath.Join(dir, "users.yml"))
+		body := []byte(`{ "address": { "city": 1 } }`)
+
+		errors := make(map[string]error)
+		e.verifyBodySchema(suite.TestCases[0], &MeasureBody{ MatchesSchema: &SchemaSource{ Path: "schemas/user.yml" } }, body, errors)
+		assert.Equal(t, 1, len(errors))
+		assert.NotNil(t, errors["Body/MatchesSchema/address.city"])
+
+		// the references of inline schemas are relative to the test suite
+		errors = make(map[string]error)
+		inline := &SchemaSource{ Inline: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"address": map[string]interface{}{ "$ref": "schemas/address.json#/definitions/address" },
+			},
+		} }
+		e.verifyBodySchema(suite.TestCases[0], &MeasureBody{ MatchesSchema: inline }, body, errors)
+		assert.Equal(t, 1, len(errors))
+		assert.NotNil(t, errors["Body/MatchesSchema/address.city"])
+
+		errors = make(map[string]error)
+		missing := &SchemaSource{ Inline: map[string]interface{}{ "$ref": "schemas/none.json" } }
+		e.verifyBodySchema(suite.TestCases[0], &MeasureBody{ MatchesSchema: missing }, body, errors)
+		assert.Contains(t, errors["Body/MatchesSchema"].Error(), "Cannot open the schema file [schemas/none.json]")
+	})
+}
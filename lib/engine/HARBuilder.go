@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/har"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// the headers which are managed by the HTTP client (or HTTP/2 pseudo-headers)
+var HAR_TRANSPORT_HEADERS = []string{ ":*", "host", "connection", "content-length", "accept-encoding", "transfer-encoding" }
+
+// the request headers which are dropped if no others are specified
+var HAR_NOISY_HEADERS = []string{ "cookie", "traceparent", "tracestate", "baggage", "x-b3-*", "x-request-id", "x-amzn-trace-id", "sentry-trace" }
+
+type HARFilter struct {
+	HostPattern string
+	PathPattern string
+	DroppedHeaders []string
+}
+
+// builds a test suite of the entries of a HAR file, the expectations are generated from the recorded responses
+func (g *SpecBuilder) BuildTestSuiteFromHAR(archive *har.Archive, filter *HARFilter) (*TestSuite, error) {
+	if filter == nil {
+		filter = &HARFilter{}
+	}
+	var hostRe, pathRe *regexp.Regexp
+	var err error
+	if len(filter.HostPattern) > 0 {
+		if hostRe, err = regexp.Compile(filter.HostPattern); err != nil {
+			return nil, fmt.Errorf("Invalid host pattern [%s], error: %s", filter.HostPattern, err)
+		}
+	}
+	if len(filter.PathPattern) > 0 {
+		if pathRe, err = regexp.Compile(filter.PathPattern); err != nil {
+			return nil, fmt.Errorf("Invalid path pattern [%s], error: %s", filter.PathPattern, err)
+		}
+	}
+	dropped := HAR_NOISY_HEADERS
+	if filter.DroppedHeaders != nil {
+		dropped = filter.DroppedHeaders
+	}
+	dropped = append(utils.Map(dropped, toLowerCase), HAR_TRANSPORT_HEADERS...)
+
+	suite := &TestSuite{ TestCases: make([]*TestCase, 0) }
+	titles := make(map[string]bool)
+	for i, entry := range archive.Log.Entries {
+		if entry == nil || entry.Request == nil || entry.Response == nil || entry.Response.Status == 0 {
+			continue
+		}
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		if hostRe != nil && !hostRe.MatchString(u.Host) {
+			continue
+		}
+		if pathRe != nil && !pathRe.MatchString(u.Path) {
+			continue
+		}
+		testcase, err := g.buildTestCaseFromHAR(entry, dropped)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid entry #%d [%s %s], error: %s", i, entry.Request.Method, entry.Request.URL, err)
+		}
+		testcase.Title = uniqueTitle(testcase.Title, func(title string) bool { return titles[title] })
+		titles[testcase.Title] = true
+		suite.TestCases = append(suite.TestCases, testcase)
+	}
+	return suite, nil
+}
+
+func (g *SpecBuilder) buildTestCaseFromHAR(entry *har.Entry, dropped []string) (*TestCase, error) {
+	body, err := entry.Response.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &client.HttpRequest{
+		Method: strings.ToUpper(entry.Request.Method),
+		Url: entry.Request.URL,
+		Body: entry.Request.GetBody(),
+	}
+	for _, header := range entry.Request.Headers {
+		if !matchAnyHeader(dropped, header.Name) {
+			req.Headers = append(req.Headers, client.HttpHeader{ Name: header.Name, Value: header.Value })
+		}
+	}
+
+	// the response body of HAR is decoded, the transport headers are not received by the client
+	res := &client.HttpResponse{ StatusCode: entry.Response.Status, Header: http.Header{}, Body: body }
+	for _, header := range entry.Response.Headers {
+		name := strings.ToLower(header.Name)
+		if name == "content-encoding" || matchAnyHeader(HAR_TRANSPORT_HEADERS, name) {
+			continue
+		}
+		res.Header.Add(header.Name, header.Value)
+	}
+
+	s := &TestCase{}
+	s.Title = GenerateTitle(req)
+	if len(g.Version) > 0 {
+		s.Version = utils.RefOfString(g.Version)
+	}
+	s.Request = req
+	s.Expectation = g.GenerateExpectation(res)
+	s.CreatedTime = utils.RefOfString(time.Now().Format(time.RFC3339))
+	s.Tags = []string{ "har" }
+	return s, nil
+}
+
+func matchAnyHeader(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
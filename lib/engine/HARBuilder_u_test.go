@@ -0,0 +1,112 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/har"
+)
+
+var recording = []byte(`{
+	"log": {
+		"version": "1.2",
+		"entries": [
+			{
+				"request": {
+					"method": "GET",
+					"url": "https://api.example.com/v1/pets?limit=2",
+					"headers": [
+						{ "name": ":authority", "value": "api.example.com" },
+						{ "name": "Accept", "value": "application/json" },
+						{ "name": "Accept-Encoding", "value": "gzip" },
+						{ "name": "Cookie", "value": "session=abc" },
+						{ "name": "traceparent", "value": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" }
+					]
+				},
+				"response": {
+					"status": 200,
+					"headers": [
+						{ "name": "Content-Type", "value": "application/json" },
+						{ "name": "Content-Encoding", "value": "gzip" }
+					],
+					"content": {
+						"mimeType": "application/json",
+						"text": "eyAiaWQiOiAxIH0=",
+						"encoding": "base64"
+					}
+				}
+			},
+			{
+				"request": {
+					"method": "post",
+					"url": "https://api.example.com/v1/pets",
+					"headers": [],
+					"postData": {
+						"mimeType": "application/x-www-form-urlencoded",
+						"params": [ { "name": "name", "value": "Kitty" } ]
+					}
+				},
+				"response": {
+					"status": 201,
+					"headers": [],
+					"content": { "mimeType": "text/plain", "text": "created" }
+				}
+			},
+			{
+				"request": { "method": "GET", "url": "https://cdn.example.com/v1/pets" },
+				"response": { "status": 200, "headers": [] }
+			},
+			{
+				"request": { "method": "GET", "url": "https://api.example.com/v1/pets" },
+				"response": { "status": 0, "headers": [] }
+			},
+			{
+				"request": { "method": "GET", "url": "https://api.example.com/v1/pets" },
+				"response": { "status": 304, "headers": [] }
+			}
+		]
+	}
+}`)
+
+func TestSpecBuilder_BuildTestSuiteFromHAR(t *testing.T) {
+	archive, err := har.ParseArchive(recording)
+	assert.Nil(t, err)
+	g, _ := NewSpecBuilder()
+
+	t.Run("Entries are converted to testcases", func(t *testing.T) {
+		suite, err := g.BuildTestSuiteFromHAR(archive, &HARFilter{ HostPattern: `^api\.` })
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(suite.TestCases))
+
+		get := suite.TestCases[0]
+		assert.Equal(t, "GET v1 pets", get.Title)
+		assert.Equal(t, "https://api.example.com/v1/pets?limit=2", get.Request.Url)
+		assert.Equal(t, 1, len(get.Request.Headers))
+		assert.Equal(t, "Accept", get.Request.Headers[0].Name)
+		assert.Equal(t, 200, *get.Expectation.StatusCode.Is.EqualTo.(*int))
+		assert.Equal(t, 1, len(get.Expectation.Headers.Items))
+		assert.Equal(t, "id", *get.Expectation.Body.Fields[0].Path)
+
+		post := suite.TestCases[1]
+		assert.Equal(t, "POST", post.Request.Method)
+		assert.Equal(t, "name=Kitty", post.Request.Body)
+		assert.Equal(t, "created", *post.Expectation.Body.IsEqualTo)
+
+		assert.Equal(t, "GET v1 pets (2)", suite.TestCases[2].Title)
+	})
+
+	t.Run("Filter by path & keep the specified headers", func(t *testing.T) {
+		suite, err := g.BuildTestSuiteFromHAR(archive, &HARFilter{ PathPattern: `^/v1/pets$`, DroppedHeaders: []string{} })
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(suite.TestCases))
+		assert.Equal(t, "https://cdn.example.com/v1/pets", suite.TestCases[2].Request.Url)
+
+		suite, err = g.BuildTestSuiteFromHAR(archive, &HARFilter{ PathPattern: `pets`, DroppedHeaders: []string{} })
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(suite.TestCases[0].Request.Headers))
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := g.BuildTestSuiteFromHAR(archive, &HARFilter{ HostPattern: `(` })
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/openapi"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const (
+	GROUP_BY_TAG = "tag"
+	GROUP_BY_PATH = "path"
+	DEFAULT_GROUP = "default"
+)
+
+// builds the test suites (one per tag or path) of the operations of an OpenAPI document
+func (g *SpecBuilder) BuildTestSuites(doc *openapi.Document, groupBy string) (map[string]*TestSuite, error) {
+	switch(groupBy) {
+	case "", GROUP_BY_TAG, GROUP_BY_PATH:
+	default:
+		return nil, fmt.Errorf("Unsupported grouping [%s], must be [%s] or [%s]", groupBy, GROUP_BY_TAG, GROUP_BY_PATH)
+	}
+	suites := make(map[string]*TestSuite)
+	for _, op := range doc.Operations {
+		name := op.Path
+		if groupBy != GROUP_BY_PATH {
+			name = DEFAULT_GROUP
+			if len(op.Tags) > 0 {
+				name = op.Tags[0]
+			}
+		}
+		suite, ok := suites[name]
+		if !ok {
+			suite = &TestSuite{ TestCases: make([]*TestCase, 0) }
+			suites[name] = suite
+		}
+		suite.TestCases = append(suite.TestCases, g.buildTestCase(doc, op))
+	}
+	return suites, nil
+}
+
+func (g *SpecBuilder) WriteTestSuite(w io.Writer, suite *TestSuite) error {
+	script, err := marshalGenerated(suite)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(script)
+	return err
+}
+
+func (g *SpecBuilder) buildTestCase(doc *openapi.Document, op *openapi.Operation) *TestCase {
+	s := &TestCase{}
+	s.Title = op.String()
+	if len(op.Summary) > 0 {
+		s.Title += " - " + op.Summary
+	}
+	s.Title = utils.StandardizeTestCaseTitle(s.Title)
+	if len(g.Version) > 0 {
+		s.Version = utils.RefOfString(g.Version)
+	}
+	s.CreatedTime = utils.RefOfString(time.Now().Format(time.RFC3339))
+	s.Tags = []string{ "openapi" }
+	for _, tag := range op.Tags {
+		if label, err := utils.StandardizeTagLabel(tag); err == nil && !utils.Contains(s.Tags, label) {
+			s.Tags = append(s.Tags, label)
+		}
+	}
+
+	code, response, is := findSuccessResponse(op)
+	accept := "*/*"
+	e := &Expectation{}
+	if is != nil {
+		e.StatusCode = &MeasureStatusCode{ Is: is }
+	}
+	if content, ok := response["content"].(map[string]interface{}); ok && len(content) > 0 {
+		mediaType := findJSONMediaType(content)
+		if len(mediaType) > 0 {
+			accept = mediaType
+			if media, ok := content[mediaType].(map[string]interface{}); ok && media["schema"] != nil {
+				e.Body = &MeasureBody{
+					HasFormat: utils.RefOfString(utils.BODY_FORMAT_JSON),
+					MatchesSchema: &SchemaSource{ Inline: doc.InlineSchema(media["schema"]) },
+				}
+			}
+		} else {
+			accept = sortedKeys(content)[0]
+		}
+	}
+	if len(code) > 0 {
+		s.Expectation = e
+	}
+
+	s.Request = g.buildRequest(doc, op, accept)
+	return s
+}
+
+func (g *SpecBuilder) buildRequest(doc *openapi.Document, op *openapi.Operation, accept string) *client.HttpRequest {
+	req := &client.HttpRequest{ Method: op.Method }
+	req.Headers = []client.HttpHeader{
+		{ Name: "Accept", Value: accept },
+	}
+
+	path := op.Path
+	for _, param := range op.Parameters {
+		name, _ := param["name"].(string)
+		required, _ := param["required"].(bool)
+		switch(param["in"]) {
+		case "path":
+			path = strings.Replace(path, "{" + name + "}", url.PathEscape(doc.SampleParameter(param)), -1)
+		case "query":
+			if required {
+				req.Query = append(req.Query, client.HttpQueryParam{ Name: name, Value: doc.SampleParameter(param) })
+			}
+		case "header":
+			if required {
+				req.Headers = append(req.Headers, client.HttpHeader{ Name: name, Value: doc.SampleParameter(param) })
+			}
+		}
+	}
+	req.Path = doc.GetBasePath() + path
+
+	if content, ok := op.RequestBody["content"].(map[string]interface{}); ok {
+		if mediaType := findJSONMediaType(content); len(mediaType) > 0 {
+			media, _ := content[mediaType].(map[string]interface{})
+			if out, err := json.MarshalIndent(doc.SampleMedia(media), "", "  "); err == nil {
+				req.Headers = append(req.Headers, client.HttpHeader{ Name: "Content-Type", Value: mediaType })
+				req.Body = string(out)
+			}
+		}
+	}
+	return req
+}
+
+// the lowest declared 2xx status code, then the 2XX range
+func findSuccessResponse(op *openapi.Operation) (string, map[string]interface{}, *ComparisonOperators) {
+	codes := make([]interface{}, 0)
+	for _, key := range sortedKeys(op.Responses) {
+		if code, err := strconv.Atoi(key); err == nil && code >= 200 && code < 300 {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 1 {
+		code := fmt.Sprintf("%v", codes[0])
+		return code, op.Responses[code], &ComparisonOperators{ EqualTo: codes[0] }
+	}
+	if len(codes) > 1 {
+		code := fmt.Sprintf("%v", codes[0])
+		return code, op.Responses[code], &ComparisonOperators{ MemberOf: codes }
+	}
+	if response, ok := op.Responses["2XX"]; ok {
+		return "2XX", response, &ComparisonOperators{ GTE: 200, LT: 300 }
+	}
+	return "", nil, nil
+}
+
+func findJSONMediaType(content map[string]interface{}) string {
+	for _, mediaType := range sortedKeys(content) {
+		if strings.Contains(strings.ToLower(mediaType), "json") {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	switch v := m.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]map[string]interface{}:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
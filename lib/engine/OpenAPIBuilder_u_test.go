@@ -0,0 +1,99 @@
+package engine
+
+import(
+	"bytes"
+	"testing"
+	"gopkg.in/yaml.v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/openapi"
+)
+
+func TestSpecBuilder_BuildTestSuites(t *testing.T) {
+	doc, err := openapi.ParseDocument([]byte(`
+openapi: 3.0.1
+info: { title: Petstore, version: 1.0.0 }
+servers:
+  - url: http://localhost:8080/api
+paths:
+  /pets:
+    get:
+      tags: [ pets ]
+      summary: List the pets
+      parameters:
+        - { name: limit, in: query, required: true, schema: { type: integer, minimum: 5 } }
+        - { name: offset, in: query, schema: { type: integer } }
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: { type: array, items: { $ref: '#/components/schemas/Pet' } }
+    post:
+      tags: [ pets ]
+      requestBody:
+        content:
+          application/json:
+            example: { name: Kitty }
+      responses:
+        '201': { description: created }
+        '202': { description: accepted }
+  /pets/{id}:
+    parameters:
+      - { name: id, in: path, required: true, example: 12 }
+    delete:
+      responses:
+        '2XX': { description: deleted }
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: { type: string }
+`))
+	assert.Nil(t, err)
+	g, _ := NewSpecBuilder()
+
+	suites, err := g.BuildTestSuites(doc, GROUP_BY_TAG)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(suites))
+	assert.Equal(t, 1, len(suites[DEFAULT_GROUP].TestCases))
+
+	list := suites["pets"].TestCases[0]
+	assert.Equal(t, "GET pets - List the pets", list.Title)
+	assert.Equal(t, []string{ "openapi", "pets" }, list.Tags)
+	assert.Equal(t, "/api/pets", list.Request.Path)
+	assert.Equal(t, []client.HttpQueryParam{ { Name: "limit", Value: "5" } }, list.Request.Query)
+	assert.Equal(t, "application/json", list.Request.Headers[0].Value)
+	assert.Equal(t, 200, list.Expectation.StatusCode.Is.EqualTo)
+	assert.NotNil(t, list.Expectation.Body.MatchesSchema.Inline)
+
+	create := suites["pets"].TestCases[1]
+	assert.Equal(t, "{\n  \"name\": \"Kitty\"\n}", create.Request.Body)
+	assert.Equal(t, []interface{}{ 201, 202 }, create.Expectation.StatusCode.Is.MemberOf)
+	assert.Nil(t, create.Expectation.Body)
+
+	remove := suites[DEFAULT_GROUP].TestCases[0]
+	assert.Equal(t, "/api/pets/12", remove.Request.Path)
+	assert.Equal(t, 200, remove.Expectation.StatusCode.Is.GTE)
+
+	t.Run("Group by path", func(t *testing.T) {
+		suites, err := g.BuildTestSuites(doc, GROUP_BY_PATH)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(suites["/pets"].TestCases))
+		assert.Equal(t, 1, len(suites["/pets/{id}"].TestCases))
+
+		_, err = g.BuildTestSuites(doc, "operation")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("The generated test suite can be loaded", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, g.WriteTestSuite(&buf, suites["pets"]))
+		loaded := &TestSuite{}
+		assert.Nil(t, yaml.Unmarshal(buf.Bytes(), loaded))
+		assert.Equal(t, 2, len(loaded.TestCases))
+		assert.Equal(t, "List the pets", loaded.TestCases[0].Title[len("GET pets - "):])
+		assert.NotNil(t, loaded.TestCases[0].Expectation.Body.MatchesSchema.Inline)
+	})
+}
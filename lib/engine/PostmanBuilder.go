@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/postman"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+var postmanVarRe = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+var envExpressionRe = regexp.MustCompile(`\$\{\{\s*env\.(` + utils.ENV_VAR_PATTERN + `)\s*(:-([^}]*))?\}\}`)
+var envVarCharRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+var postmanStatusRe = regexp.MustCompile(`pm\.response\.to\.have\.status\((\d+)\)`)
+
+// builds the test suites (one per folder) of the requests of a Postman collection, the warnings describe the unsupported parts
+func (g *SpecBuilder) BuildTestSuitesFromPostman(collection *postman.Collection) (map[string]*TestSuite, []string) {
+	b := &postmanImport{
+		builder: g,
+		variables: make(map[string]string),
+		suites: make(map[string]*TestSuite),
+		warnings: make([]string, 0),
+	}
+	for _, v := range collection.Variables {
+		if v != nil {
+			b.variables[v.Key] = v.Value
+		}
+	}
+	b.walk(collection.Items, collection.Info.Name, collection.Auth)
+	return b.suites, b.warnings
+}
+
+type postmanImport struct {
+	builder *SpecBuilder
+	variables map[string]string
+	suites map[string]*TestSuite
+	warnings []string
+}
+
+func (b *postmanImport) walk(items []*postman.Item, name string, auth *postman.Auth) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if item.IsFolder() {
+			b.walk(item.Items, strings.TrimSpace(name + " " + item.Name), itemAuth)
+			continue
+		}
+		suite, ok := b.suites[name]
+		if !ok {
+			suite = &TestSuite{ TestCases: make([]*TestCase, 0) }
+			b.suites[name] = suite
+		}
+		testcase := b.buildTestCase(item, itemAuth)
+		testcase.Title = uniqueTitle(testcase.Title, func(title string) bool { return findTestCase(suite, title) != nil })
+		suite.TestCases = append(suite.TestCases, testcase)
+	}
+}
+
+func (b *postmanImport) buildTestCase(item *postman.Item, auth *postman.Auth) *TestCase {
+	g := b.builder
+	s := &TestCase{}
+	s.Request = b.buildRequest(item, auth)
+	s.Title = utils.StandardizeTestCaseTitle(item.Name)
+	if len(s.Title) == 0 {
+		s.Title = GenerateTitle(s.Request)
+	}
+	if len(g.Version) > 0 {
+		s.Version = utils.RefOfString(g.Version)
+	}
+	s.CreatedTime = utils.RefOfString(time.Now().Format(time.RFC3339))
+	s.Tags = []string{ "postman" }
+
+	// the first saved example, then the status code of the test scripts
+	for _, example := range item.Responses {
+		if example == nil || example.Code == 0 {
+			continue
+		}
+		res := &client.HttpResponse{ StatusCode: example.Code, Header: http.Header{}, Body: []byte(example.Body) }
+		for _, header := range example.Headers {
+			if header != nil && !header.Disabled && !matchAnyHeader(HAR_TRANSPORT_HEADERS, header.Key) {
+				res.Header.Add(header.Key, header.Value)
+			}
+		}
+		s.Expectation = g.GenerateExpectation(res)
+		return s
+	}
+	for _, event := range item.Events {
+		if event == nil || event.Listen != "test" || event.Script == nil {
+			continue
+		}
+		if m := postmanStatusRe.FindStringSubmatch(strings.Join(event.Script.Exec, "\n")); m != nil {
+			code, _ := strconv.Atoi(m[1])
+			s.Expectation = &Expectation{
+				StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ EqualTo: code } },
+			}
+		}
+	}
+	return s
+}
+
+func (b *postmanImport) buildRequest(item *postman.Item, auth *postman.Auth) *client.HttpRequest {
+	src := item.Request
+	req := &client.HttpRequest{ Method: strings.ToUpper(src.Method) }
+	if len(req.Method) == 0 {
+		req.Method = "GET"
+	}
+	if src.URL != nil {
+		req.Url = b.convert(item, src.URL.Raw)
+	}
+	for _, header := range src.Headers {
+		if header != nil && !header.Disabled {
+			req.Headers = append(req.Headers, client.HttpHeader{ Name: header.Key, Value: b.convert(item, header.Value) })
+		}
+	}
+
+	if src.Auth != nil {
+		auth = src.Auth
+	}
+	if auth != nil {
+		switch(auth.Type) {
+		case "noauth":
+		case "bearer":
+			req.Headers = append(req.Headers, client.HttpHeader{ Name: "Authorization", Value: "Bearer " + b.convert(item, auth.Get(auth.Bearer, "token")) })
+		case "basic":
+			credentials := auth.Get(auth.Basic, "username") + ":" + auth.Get(auth.Basic, "password")
+			if postmanVarRe.MatchString(credentials) {
+				b.warn(item, "the variables of the basic authentication are not supported")
+			} else {
+				req.Headers = append(req.Headers, client.HttpHeader{ Name: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)) })
+			}
+		case "apikey":
+			if auth.Get(auth.APIKey, "in") == "query" {
+				b.warn(item, "the API key in the query string is not supported")
+			} else {
+				req.Headers = append(req.Headers, client.HttpHeader{ Name: b.convert(item, auth.Get(auth.APIKey, "key")), Value: b.convert(item, auth.Get(auth.APIKey, "value")) })
+			}
+		default:
+			b.warn(item, fmt.Sprintf("the authentication [%s] is not supported", auth.Type))
+		}
+	}
+
+	if src.Body != nil {
+		switch(src.Body.Mode) {
+		case "raw":
+			req.Body = b.convert(item, src.Body.Raw)
+		case "urlencoded":
+			values := make([]string, 0)
+			for _, param := range src.Body.URLEncoded {
+				if param != nil && !param.Disabled {
+					values = append(values, url.QueryEscape(param.Key) + "=" + url.QueryEscape(param.Value))
+				}
+			}
+			req.Body = b.convert(item, strings.Join(values, "&"))
+			if !hasHeader(req.Headers, "Content-Type") {
+				req.Headers = append(req.Headers, client.HttpHeader{ Name: "Content-Type", Value: "application/x-www-form-urlencoded" })
+			}
+		case "":
+		default:
+			b.warn(item, fmt.Sprintf("the body mode [%s] is not supported", src.Body.Mode))
+		}
+	}
+	return req
+}
+
+// converts the Postman variables {{name}} to the environment variables ${{ env.name :- value }}
+func (b *postmanImport) convert(item *postman.Item, text string) string {
+	return postmanVarRe.ReplaceAllStringFunc(text, func(exp string) string {
+		name := postmanVarRe.FindStringSubmatch(exp)[1]
+		if strings.HasPrefix(name, "$") {
+			// the dynamic variables of Postman have no equivalent, they are sent as is
+			b.warn(item, fmt.Sprintf("the dynamic variable [%s] is not supported", exp))
+			return exp
+		}
+		envName := envVarCharRe.ReplaceAllString(name, "_")
+		if envName[0] >= '0' && envName[0] <= '9' {
+			envName = "_" + envName
+		}
+		value := strings.TrimSpace(b.variables[name])
+		if len(value) > 0 && !strings.Contains(value, "}") {
+			return fmt.Sprintf("${{ env.%s :- %s }}", envName, value)
+		}
+		return fmt.Sprintf("${{ env.%s }}", envName)
+	})
+}
+
+func (b *postmanImport) warn(item *postman.Item, msg string) {
+	b.warnings = append(b.warnings, fmt.Sprintf("[%s] %s", item.Name, msg))
+}
+
+// builds a Postman collection of the testcases (a folder per group), the environment variables become collection variables
+func (g *SpecBuilder) BuildPostmanCollection(name string, pdp string, groups map[string][]*TestCase) *postman.Collection {
+	collection := postman.NewCollection(name)
+	variables := make(map[string]string)
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		folder := &postman.Item{ Name: group, Items: make([]*postman.Item, 0) }
+		for _, testcase := range groups[group] {
+			if testcase == nil || testcase.Request == nil {
+				continue
+			}
+			folder.Items = append(folder.Items, exportTestCase(testcase, pdp, variables))
+		}
+		collection.Items = append(collection.Items, folder)
+	}
+
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		collection.Variables = append(collection.Variables, &postman.Variable{ Key: key, Value: variables[key] })
+	}
+	return collection
+}
+
+func exportTestCase(testcase *TestCase, pdp string, variables map[string]string) *postman.Item {
+	req := testcase.Request
+	if len(req.Url) == 0 && len(req.PDP) == 0 && len(pdp) > 0 {
+		clone := *req
+		clone.PDP = pdp
+		req = &clone
+	}
+	convert := func(text string) string {
+		return envExpressionRe.ReplaceAllStringFunc(text, func(exp string) string {
+			m := envExpressionRe.FindStringSubmatch(exp)
+			if len(variables[m[1]]) == 0 {
+				variables[m[1]] = strings.TrimSpace(m[3])
+			}
+			return "{{" + m[1] + "}}"
+		})
+	}
+
+	method := req.Method
+	if len(method) == 0 {
+		method = "GET"
+	}
+	item := &postman.Item{
+		Name: testcase.Title,
+		Request: &postman.Request{
+			Method: method,
+			URL: &postman.URL{ Raw: convert(client.BuildUrl(req)) },
+		},
+		Responses: make([]*postman.Response, 0),
+	}
+	for _, header := range req.Headers {
+		item.Request.Headers = append(item.Request.Headers, &postman.KeyValue{ Key: header.Name, Value: convert(header.Value) })
+	}
+	if len(req.Body) > 0 {
+		item.Request.Body = &postman.Body{ Mode: "raw", Raw: convert(req.Body) }
+	}
+
+	// the expected status codes become a test script
+	if e := testcase.Expectation; e != nil && e.StatusCode != nil && e.StatusCode.Is != nil {
+		exec := make([]string, 0)
+		if code, ok := toStatusCode(e.StatusCode.Is.EqualTo); ok {
+			exec = append(exec, fmt.Sprintf("pm.test(\"Status code is %d\", function () {", code),
+				fmt.Sprintf("    pm.response.to.have.status(%d);", code), "});")
+		} else if len(e.StatusCode.Is.MemberOf) > 0 {
+			codes := make([]string, 0)
+			for _, item := range e.StatusCode.Is.MemberOf {
+				if code, ok := toStatusCode(item); ok {
+					codes = append(codes, strconv.Itoa(code))
+				}
+			}
+			exec = append(exec, "pm.test(\"Status code is expected\", function () {",
+				fmt.Sprintf("    pm.expect(pm.response.code).to.be.oneOf([%s]);", strings.Join(codes, ", ")), "});")
+		}
+		if len(exec) > 0 {
+			item.Events = []*postman.Event{
+				{ Listen: "test", Script: &postman.Script{ Type: "text/javascript", Exec: exec } },
+			}
+		}
+	}
+	return item
+}
+
+func toStatusCode(val interface{}) (int, bool) {
+	switch v := val.(type) {
+	case int:
+		return v, true
+	case *int:
+		if v != nil {
+			return *v, true
+		}
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func hasHeader(headers []client.HttpHeader, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func findTestCase(suite *TestSuite, title string) *TestCase {
+	for _, testcase := range suite.TestCases {
+		if testcase != nil && testcase.Title == title {
+			return testcase
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/postman"
+)
+
+var collection = []byte(`{
+	"info": {
+		"name": "Petstore",
+		"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	},
+	"auth": {
+		"type": "bearer",
+		"bearer": [ { "key": "token", "value": "{{token}}" } ]
+	},
+	"variable": [
+		{ "key": "baseUrl", "value": "http://localhost:8080" },
+		{ "key": "token", "value": "" }
+	],
+	"item": [
+		{
+			"name": "Health check",
+			"request": { "method": "GET", "url": "{{baseUrl}}/health", "auth": { "type": "noauth" } },
+			"event": [
+				{ "listen": "test", "script": { "type": "text/javascript", "exec": [ "pm.response.to.have.status(204);" ] } }
+			]
+		},
+		{
+			"name": "Pets",
+			"item": [
+				{
+					"name": "Create a pet",
+					"request": {
+						"method": "POST",
+						"header": [
+							{ "key": "Content-Type", "value": "application/json" },
+							{ "key": "X-Debug", "value": "1", "disabled": true }
+						],
+						"url": { "raw": "{{baseUrl}}/pets?dry-run={{$randomInt}}", "host": [ "{{baseUrl}}" ] },
+						"body": { "mode": "raw", "raw": "{ \"name\": \"{{pet-name}}\" }" }
+					},
+					"response": [
+						{
+							"name": "Created",
+							"code": 201,
+							"header": [ { "key": "Content-Type", "value": "application/json" } ],
+							"body": "{ \"id\": 1 }"
+						}
+					]
+				},
+				{
+					"name": "Upload a photo",
+					"request": {
+						"method": "POST",
+						"url": "{{baseUrl}}/pets/1/photo",
+						"body": { "mode": "formdata", "formdata": [ { "key": "file", "type": "file", "src": "cat.png" } ] }
+					}
+				}
+			]
+		}
+	]
+}`)
+
+func TestSpecBuilder_BuildTestSuitesFromPostman(t *testing.T) {
+	c, err := postman.ParseCollection(collection)
+	assert.Nil(t, err)
+	g, _ := NewSpecBuilder()
+	suites, warnings := g.BuildTestSuitesFromPostman(c)
+	assert.Equal(t, 2, len(suites))
+	assert.Equal(t, []string{
+		"[Create a pet] the dynamic variable [{{$randomInt}}] is not supported",
+		"[Upload a photo] the body mode [formdata] is not supported",
+	}, warnings)
+
+	health := suites["Petstore"].TestCases[0]
+	assert.Equal(t, "Health check", health.Title)
+	assert.Equal(t, "${{ env.baseUrl :- http://localhost:8080 }}/health", health.Request.Url)
+	assert.Equal(t, 0, len(health.Request.Headers))
+	assert.Equal(t, 204, health.Expectation.StatusCode.Is.EqualTo)
+
+	create := suites["Petstore Pets"].TestCases[0]
+	assert.Equal(t, "${{ env.baseUrl :- http://localhost:8080 }}/pets?dry-run={{$randomInt}}", create.Request.Url)
+	assert.Equal(t, []client.HttpHeader{
+		{ Name: "Content-Type", Value: "application/json" },
+		{ Name: "Authorization", Value: "Bearer ${{ env.token }}" },
+	}, create.Request.Headers)
+	assert.Equal(t, `{ "name": "${{ env.pet_name }}" }`, create.Request.Body)
+	assert.Equal(t, 201, *create.Expectation.StatusCode.Is.EqualTo.(*int))
+	assert.Equal(t, "id", *create.Expectation.Body.Fields[0].Path)
+
+	t.Run("Only Postman Collection v2.x is supported", func(t *testing.T) {
+		_, err := postman.ParseCollection([]byte(`{ "info": { "name": "v1" } }`))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSpecBuilder_BuildPostmanCollection(t *testing.T) {
+	g, _ := NewSpecBuilder()
+	code := 200
+	c := g.BuildPostmanCollection("Exported", "http://localhost:17779", map[string][]*TestCase{
+		"tests/pets": []*TestCase{
+			{
+				Title: "List the pets",
+				Request: &client.HttpRequest{
+					Method: "GET",
+					Path: "/pets",
+					Headers: []client.HttpHeader{ { Name: "Authorization", Value: "Bearer ${{ env.TOKEN :- secret }}" } },
+				},
+				Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ EqualTo: &code } } },
+			},
+			{
+				Title: "Create a pet",
+				Request: &client.HttpRequest{
+					Method: "POST",
+					Url: "${{ env.BASE_URL }}/pets",
+					Body: `{ "name": "Kitty", "owner": "${{ env.TOKEN }}" }`,
+				},
+				Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ MemberOf: []interface{}{ 200, 201 } } } },
+			},
+		},
+	})
+	assert.Equal(t, postman.SCHEMA_V2_1, c.Info.Schema)
+	assert.Equal(t, 1, len(c.Items))
+	assert.Equal(t, "tests/pets", c.Items[0].Name)
+
+	list := c.Items[0].Items[0]
+	assert.Equal(t, "http://localhost:17779/pets", list.Request.URL.Raw)
+	assert.Equal(t, "Bearer {{TOKEN}}", list.Request.Headers[0].Value)
+	assert.Equal(t, "    pm.response.to.have.status(200);", list.Events[0].Script.Exec[1])
+
+	create := c.Items[0].Items[1]
+	assert.Equal(t, "{{BASE_URL}}/pets", create.Request.URL.Raw)
+	assert.Equal(t, `{ "name": "Kitty", "owner": "{{TOKEN}}" }`, create.Request.Body.Raw)
+	assert.Equal(t, "    pm.expect(pm.response.code).to.be.oneOf([200, 201]);", create.Events[0].Script.Exec[1])
+
+	assert.Equal(t, []*postman.Variable{
+		{ Key: "BASE_URL", Value: "" },
+		{ Key: "TOKEN", Value: "secret" },
+	}, c.Variables)
+}
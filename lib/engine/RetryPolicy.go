@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const DEFAULT_RETRY_ATTEMPTS int = 3
+const DEFAULT_RETRY_INTERVAL time.Duration = time.Second
+
+type retryPolicy struct {
+	maxAttempts int
+	interval time.Duration
+	backoff float64
+	maxInterval time.Duration
+	until *Expectation
+}
+
+// the durations have been validated by the schema of the test suites
+func newRetryPolicy(section *SectionRetry) *retryPolicy {
+	if section == nil {
+		return nil
+	}
+	p := &retryPolicy{
+		maxAttempts: DEFAULT_RETRY_ATTEMPTS,
+		interval: DEFAULT_RETRY_INTERVAL,
+		backoff: 1,
+		until: section.Until,
+	}
+	if section.MaxAttempts != nil {
+		p.maxAttempts = *section.MaxAttempts
+	}
+	if section.Interval != nil {
+		if interval, err := time.ParseDuration(*section.Interval); err == nil {
+			p.interval = interval
+		}
+	}
+	if section.Backoff != nil && *section.Backoff >= 1 {
+		p.backoff = *section.Backoff
+	}
+	if section.MaxInterval != nil {
+		if maxInterval, err := time.ParseDuration(*section.MaxInterval); err == nil {
+			p.maxInterval = maxInterval
+		}
+	}
+	return p
+}
+
+// waits before the next attempt, returns false when the attempts are exhausted
+func (p *retryPolicy) next(attempts int) bool {
+	if p == nil || attempts >= p.maxAttempts {
+		return false
+	}
+	time.Sleep(p.delay(attempts))
+	return true
+}
+
+func (p *retryPolicy) delay(attempts int) time.Duration {
+	delay := float64(p.interval)
+	for i := 1; i < attempts; i++ {
+		delay = delay * p.backoff
+		if p.maxInterval > 0 && delay >= float64(p.maxInterval) {
+			return p.maxInterval
+		}
+	}
+	if p.maxInterval > 0 && delay > float64(p.maxInterval) {
+		return p.maxInterval
+	}
+	return time.Duration(delay)
+}
+
+func combineErrorMap(title string, errors map[string]error) error {
+	keys := make([]string, 0, len(errors))
+	for key := range errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, errors[key]))
+	}
+	return utils.CombineErrors(title, lines)
+}
@@ -0,0 +1,26 @@
+package engine
+
+import(
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestRetryPolicy_delay(t *testing.T) {
+	backoff := 2.0
+	p := newRetryPolicy(&SectionRetry{
+		Interval: utils.RefOfString("100ms"),
+		Backoff: &backoff,
+		MaxInterval: utils.RefOfString("300ms"),
+	})
+	assert.Equal(t, DEFAULT_RETRY_ATTEMPTS, p.maxAttempts)
+	assert.Equal(t, 100 * time.Millisecond, p.delay(1))
+	assert.Equal(t, 200 * time.Millisecond, p.delay(2))
+	assert.Equal(t, 300 * time.Millisecond, p.delay(3))
+	assert.Equal(t, 300 * time.Millisecond, p.delay(50))
+	assert.False(t, p.next(3))
+
+	var none *retryPolicy
+	assert.False(t, none.next(1))
+}
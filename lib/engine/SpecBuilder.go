@@ -4,17 +4,46 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 	"gopkg.in/yaml.v2"
 	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/comparison"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
+const SNAPSHOT_TAG = "snapshot"
+
+const (
+	BODY_ASSERTION_INCLUDES = "includes"
+	BODY_ASSERTION_FIELDS = "fields"
+	BODY_ASSERTION_IS_EQUAL_TO = "is-equal-to"
+)
+
 type SpecBuilder struct {
 	ExcludedHeaders []string
+	VolatileHeaders []string
+	HeaderTotal bool
+	IgnoredPaths []string
+	VolatilePaths []string
+	BodyAssertions []string
 	Version string
 }
 
+// the rules of snapshot generation, the unset ones keep the defaults
+type GenerationProfile struct {
+	ExcludedHeaders []string
+	VolatileHeaders []string
+	HeaderTotal *bool
+	IgnoredPaths []string
+	VolatilePaths []string
+	BodyAssertions []string
+}
+
 func NewSpecBuilder() (*SpecBuilder, error) {
 	ref := new(SpecBuilder)
 	ref.ExcludedHeaders = []string {
@@ -22,38 +51,140 @@ func NewSpecBuilder() (*SpecBuilder, error) {
 		"date",
 		"x-exec-duration",
 	}
+	ref.HeaderTotal = true
 	return ref, nil
 }
 
+func (g *SpecBuilder) ApplyProfile(p *GenerationProfile) error {
+	if p == nil {
+		return nil
+	}
+	for _, name := range p.BodyAssertions {
+		switch(name) {
+		case BODY_ASSERTION_INCLUDES, BODY_ASSERTION_FIELDS, BODY_ASSERTION_IS_EQUAL_TO:
+		default:
+			return fmt.Errorf("Unsupported body assertion [%s], must be one of [%s, %s, %s]", name,
+				BODY_ASSERTION_INCLUDES, BODY_ASSERTION_FIELDS, BODY_ASSERTION_IS_EQUAL_TO)
+		}
+	}
+	// the whole body is compared by is-equal-to, the ignored & volatile values would still be asserted
+	if utils.Contains(p.BodyAssertions, BODY_ASSERTION_IS_EQUAL_TO) && (len(p.IgnoredPaths) > 0 || len(p.VolatilePaths) > 0) {
+		return fmt.Errorf("The body assertion [%s] cannot be combined with ignored or volatile paths, use [%s] instead",
+			BODY_ASSERTION_IS_EQUAL_TO, BODY_ASSERTION_INCLUDES)
+	}
+	for _, pattern := range append(append([]string{}, p.IgnoredPaths...), p.VolatilePaths...) {
+		for _, segment := range strings.Split(pattern, ".") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("Invalid body path pattern [%s]", pattern)
+			}
+		}
+	}
+	if p.ExcludedHeaders != nil {
+		g.ExcludedHeaders = utils.Map(p.ExcludedHeaders, toLowerCase)
+	}
+	if p.VolatileHeaders != nil {
+		g.VolatileHeaders = utils.Map(p.VolatileHeaders, toLowerCase)
+	}
+	if p.HeaderTotal != nil {
+		g.HeaderTotal = *p.HeaderTotal
+	}
+	if p.IgnoredPaths != nil {
+		g.IgnoredPaths = p.IgnoredPaths
+	}
+	if p.VolatilePaths != nil {
+		g.VolatilePaths = p.VolatilePaths
+	}
+	if p.BodyAssertions != nil {
+		g.BodyAssertions = p.BodyAssertions
+	}
+	return nil
+}
+
 func (g *SpecBuilder) GenerateTestCase(w io.Writer, req *client.HttpRequest, res *client.HttpResponse) error {
-	s := TestCase{}
+	return g.WriteSnapshot(w, g.BuildTestCase(req, res))
+}
+
+func (g *SpecBuilder) WriteSnapshot(w io.Writer, s *TestCase) error {
+	r := &GeneratedSnapshot{}
+	r.TestCases = []TestCase{*s}
+	script, err := marshalGenerated(r)
+	if err != nil {
+		fmt.Fprintln(w, err.Error())
+		return err
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, string(script))
+
+	return nil
+}
+
+// the generated snapshots & test suites are marshalled in the same way
+func marshalGenerated(obj interface{}) ([]byte, error) {
+	script, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot marshal generated testcase, error: %s", err)
+	}
+	return script, nil
+}
+
+func (g *SpecBuilder) BuildTestCase(req *client.HttpRequest, res *client.HttpResponse) *TestCase {
+	s := &TestCase{}
 	s.Title = "<Generated testcase>"
 	s.Version = utils.RefOfString(g.Version)
-	s.Request = req
-	s.Expectation = g.generateExpectation(res)
+	s.Request = structureQuery(req)
+	s.Expectation = g.GenerateExpectation(res)
 	s.CreatedTime = utils.RefOfString(time.Now().Format(time.RFC3339))
-	s.Tags = []string {"snapshot"}
+	s.Tags = []string {SNAPSHOT_TAG}
 	username, err := utils.FindUsername()
 	if err == nil {
 		if tag, err := utils.StandardizeTagLabel(username); err == nil {
 			s.Tags = append(s.Tags, tag)
 		}
 	}
+	return s
+}
+
+// the title of a generated testcase (the method & the path of the request), e.g. "GET pets"
+func GenerateTitle(req *client.HttpRequest) string {
+	title := req.Method
+	if u, err := url.Parse(client.BuildUrl(req)); err == nil {
+		title += " " + u.Path
+	}
+	return utils.StandardizeTestCaseTitle(title)
+}
 
-	r := &GeneratedSnapshot{}
-	r.TestCases = []TestCase{s}
-	script, err := yaml.Marshal(r)
-	if err != nil {
-		fmt.Fprintf(w, "Cannot marshal generated testcase, error: %s\n", err)
-		return err
+// appends a number to the title which is already taken, e.g. "GET pets (2)"
+func uniqueTitle(title string, taken func(string) bool) string {
+	unique := title
+	for n := 2; taken(unique); n++ {
+		unique = fmt.Sprintf("%s (%d)", title, n)
 	}
-	fmt.Fprintln(w)
-	fmt.Fprintln(w, string(script))
+	return unique
+}
 
-	return nil
+// the query string of the url (or path) becomes the query parameters of the request
+func structureQuery(req *client.HttpRequest) *client.HttpRequest {
+	if req == nil || len(req.Query) > 0 {
+		return req
+	}
+	clone := *req
+	if len(clone.Url) > 0 {
+		url, params, ok := client.SplitQuery(clone.Url)
+		if !ok {
+			return req
+		}
+		clone.Url, clone.Query = url, params
+	} else {
+		path, params, ok := client.SplitQuery(clone.Path)
+		if !ok {
+			return req
+		}
+		clone.Path, clone.Query = path, params
+	}
+	return &clone
 }
 
-func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation {
+func (g *SpecBuilder) GenerateExpectation(res *client.HttpResponse) *Expectation {
 	if res == nil {
 		return nil
 	}
@@ -72,15 +203,23 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 	total := len(res.Header)
 	if total > 0 {
 		e.Headers = &MeasureHeaders{
-			Total: &MeasureTotal{
+			Items: make([]MeasureHeader, 0),
+		}
+		if g.HeaderTotal {
+			e.Headers.Total = &MeasureTotal{
 				Is: &ComparisonOperators{
 					EqualTo: &total,
 				},
-			},
-			Items: make([]MeasureHeader, 0),
+			}
 		}
 		count := 0
-		for key, vals := range res.Header {
+		keys := make([]string, 0, len(res.Header))
+		for key := range res.Header {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			vals := res.Header[key]
 			if utils.ContainsInsensitiveCase(g.ExcludedHeaders, key) {
 				continue
 			}
@@ -93,11 +232,24 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 						EqualTo: &value,
 					},
 				}
+				if utils.ContainsInsensitiveCase(g.VolatileHeaders, key) {
+					one.Is = &ComparisonOperators{
+						HasType: utils.RefOfString(comparison.TYPE_STRING),
+					}
+				}
 				e.Headers.Items = append(e.Headers.Items, one)
 				count = count + 1
 			}
 		}
 	}
+	if e.Headers != nil && e.Headers.Total == nil && len(e.Headers.Items) == 0 {
+		e.Headers = nil
+	}
+
+	// the bodiless responses are verified by the status code & headers
+	if res.IsBodiless() {
+		return e
+	}
 
 	// body
 	e.Body = &MeasureBody{}
@@ -106,26 +258,26 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 	if e.Body.HasFormat == nil {
 		if err := json.Unmarshal(res.Body, &obj); err == nil {
 			e.Body.HasFormat = utils.RefOfString(utils.BODY_FORMAT_JSON)
-			var content string
-			if out, err := json.MarshalIndent(obj, "", "  "); err == nil {
-				content = string(out)
-			} else {
-				content = string(res.Body)
+			marshal := func(v interface{}) string {
+				if out, err := json.MarshalIndent(v, "", "  "); err == nil {
+					return string(out)
+				}
+				return string(res.Body)
 			}
-			e.Body.Includes = &content
+			g.generateBodyContent(e.Body, obj, marshal)
 		}
 	}
 
 	if e.Body.HasFormat == nil {
 		if err := yaml.Unmarshal(res.Body, &obj); err == nil {
 			e.Body.HasFormat = utils.RefOfString(utils.BODY_FORMAT_YAML)
-			var content string
-			if out, err := yaml.Marshal(obj); err == nil {
-				content = string(out)
-			} else {
-				content = string(res.Body)
+			marshal := func(v interface{}) string {
+				if out, err := yaml.Marshal(v); err == nil {
+					return string(out)
+				}
+				return string(res.Body)
 			}
-			e.Body.Includes = &content
+			g.generateBodyContent(e.Body, obj, marshal)
 		}
 	}
 
@@ -136,18 +288,27 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 	}
 
 	// body fields
-	if len(obj) > 0 {
+	if len(obj) > 0 && g.hasBodyAssertion(BODY_ASSERTION_FIELDS) {
 		flatten, _ := utils.Flatten("", obj)
+		keys := make([]string, 0, len(flatten))
+		for key := range flatten {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
 		fields := make([]MeasureBodyField, 0)
-		for key, val := range flatten {
-			if val != nil {
-				fields = append(fields, MeasureBodyField{
-					Path: utils.RefOfString(key),
-					Is: &ComparisonOperators{
-						EqualTo: val,
-					},
-				})
+		for _, key := range keys {
+			val := flatten[key]
+			if val == nil || matchAnyPath(g.IgnoredPaths, key) {
+				continue
 			}
+			is := &ComparisonOperators{ EqualTo: val }
+			if matchAnyPath(g.VolatilePaths, key) {
+				is = &ComparisonOperators{ HasType: utils.RefOfString(typeOfVolatile(val)) }
+			}
+			fields = append(fields, MeasureBodyField{
+				Path: utils.RefOfString(key),
+				Is: is,
+			})
 		}
 		e.Body.Fields = fields
 	}
@@ -155,6 +316,108 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 	return e
 }
 
+func toLowerCase(name string, i int) string {
+	return strings.ToLower(name)
+}
+
+func (g *SpecBuilder) generateBodyContent(body *MeasureBody, obj map[string]interface{}, marshal func(interface{}) string) {
+	if g.hasBodyAssertion(BODY_ASSERTION_INCLUDES) {
+		// the ignored & volatile values are not included
+		patterns := append(append([]string{}, g.IgnoredPaths...), g.VolatilePaths...)
+		body.Includes = utils.RefOfString(marshal(removePaths(obj, "", patterns)))
+	}
+	if g.hasBodyAssertion(BODY_ASSERTION_IS_EQUAL_TO) {
+		body.IsEqualTo = utils.RefOfString(marshal(obj))
+	}
+}
+
+func (g *SpecBuilder) hasBodyAssertion(name string) bool {
+	if g.BodyAssertions == nil {
+		return name == BODY_ASSERTION_INCLUDES || name == BODY_ASSERTION_FIELDS
+	}
+	return utils.Contains(g.BodyAssertions, name)
+}
+
+// the volatile numbers may be integers or not
+func typeOfVolatile(val interface{}) string {
+	t := comparison.TypeOf(val)
+	if t == comparison.TYPE_INTEGER {
+		return comparison.TYPE_NUMBER
+	}
+	return t
+}
+
+func removePaths(node interface{}, prefix string, patterns []string) interface{} {
+	if len(patterns) == 0 {
+		return node
+	}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			path := joinPath(prefix, key)
+			if !matchAnyPath(patterns, path) {
+				m[key] = removePaths(item, path, patterns)
+			}
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			path := joinPath(prefix, fmt.Sprintf("%v", key))
+			if !matchAnyPath(patterns, path) {
+				m[key] = removePaths(item, path, patterns)
+			}
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = removePaths(item, joinPath(prefix, strconv.Itoa(i)), patterns)
+		}
+		return list
+	}
+	return node
+}
+
+func joinPath(prefix string, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func matchAnyPath(patterns []string, fieldPath string) bool {
+	for _, pattern := range patterns {
+		if matchPath(strings.Split(pattern, "."), strings.Split(fieldPath, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// the segments of pattern are shell patterns, "**" matches any number of segments
+func matchPath(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPath(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchPath(pattern[1:], segments[1:])
+}
+
 type GeneratedSnapshot struct {
 	TestCases []TestCase `yaml:"testcase-snapshot"`
 }
@@ -0,0 +1,119 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/comparison"
+)
+
+func TestSpecBuilder_GenerateExpectation(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Date", "Mon, 01 Jul 2019 00:00:00 GMT")
+	header.Set("X-Request-Id", "a1b2c3")
+	res := &client.HttpResponse{
+		StatusCode: 200,
+		Header: header,
+		Body: []byte(`{ "id": 12, "name": "Kitty", "items": [ { "id": 1, "createdAt": "2019-07-01" } ] }`),
+	}
+
+	t.Run("Default rules", func(t *testing.T) {
+		g, _ := NewSpecBuilder()
+		e := g.GenerateExpectation(res)
+		assert.Equal(t, 2, len(e.Headers.Items))
+		assert.Equal(t, 3, *e.Headers.Total.Is.EqualTo.(*int))
+		assert.NotNil(t, e.Body.Includes)
+		assert.Nil(t, e.Body.IsEqualTo)
+		assert.Equal(t, 4, len(e.Body.Fields))
+	})
+
+	t.Run("Volatile & ignored values", func(t *testing.T) {
+		g, _ := NewSpecBuilder()
+		err := g.ApplyProfile(&GenerationProfile{
+			VolatileHeaders: []string{ "X-Request-Id" },
+			IgnoredPaths: []string{ "**.createdAt" },
+			VolatilePaths: []string{ "id", "items.*.id" },
+			BodyAssertions: []string{ BODY_ASSERTION_INCLUDES, BODY_ASSERTION_FIELDS },
+		})
+		assert.Nil(t, err)
+		e := g.GenerateExpectation(res)
+		assert.Equal(t, "X-Request-Id", *e.Headers.Items[1].Name)
+		assert.Equal(t, comparison.TYPE_STRING, *e.Headers.Items[1].Is.HasType)
+		assert.Equal(t, "{\n  \"items\": [\n    {}\n  ],\n  \"name\": \"Kitty\"\n}", *e.Body.Includes)
+		assert.Equal(t, 3, len(e.Body.Fields))
+		assert.Equal(t, "id", *e.Body.Fields[0].Path)
+		assert.Equal(t, comparison.TYPE_NUMBER, *e.Body.Fields[0].Is.HasType)
+		assert.Equal(t, "items.0.id", *e.Body.Fields[1].Path)
+		assert.Equal(t, comparison.TYPE_NUMBER, *e.Body.Fields[1].Is.HasType)
+		assert.Equal(t, "name", *e.Body.Fields[2].Path)
+	})
+
+	t.Run("Full body comparison", func(t *testing.T) {
+		g, _ := NewSpecBuilder()
+		total := false
+		err := g.ApplyProfile(&GenerationProfile{
+			ExcludedHeaders: []string{ "content-type", "date", "x-request-id" },
+			HeaderTotal: &total,
+			BodyAssertions: []string{ BODY_ASSERTION_IS_EQUAL_TO },
+		})
+		assert.Nil(t, err)
+		e := g.GenerateExpectation(res)
+		assert.Nil(t, e.Headers)
+		assert.Nil(t, e.Body.Includes)
+		assert.NotNil(t, e.Body.IsEqualTo)
+		assert.Equal(t, 0, len(e.Body.Fields))
+	})
+
+	t.Run("Unsupported body assertion", func(t *testing.T) {
+		g, _ := NewSpecBuilder()
+		err := g.ApplyProfile(&GenerationProfile{ BodyAssertions: []string{ "everything" } })
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Full body comparison with volatile paths", func(t *testing.T) {
+		g, _ := NewSpecBuilder()
+		err := g.ApplyProfile(&GenerationProfile{
+			VolatilePaths: []string{ "id" },
+			BodyAssertions: []string{ BODY_ASSERTION_INCLUDES, BODY_ASSERTION_IS_EQUAL_TO },
+		})
+		assert.Equal(t, "The body assertion [is-equal-to] cannot be combined with ignored or volatile paths, use [includes] instead", err.Error())
+	})
+}
+
+func TestSpecBuilder_BuildTestCase_Query(t *testing.T) {
+	g, _ := NewSpecBuilder()
+	req := &client.HttpRequest{ Method: "GET", Url: "http://localhost/pets?tag=a%26b&tag=c+d" }
+	s := g.BuildTestCase(req, nil)
+	assert.Equal(t, "http://localhost/pets", s.Request.Url)
+	assert.Equal(t, []client.HttpQueryParam{
+		{ Name: "tag", Value: "a&b" },
+		{ Name: "tag", Value: "c d" },
+	}, s.Request.Query)
+	assert.Equal(t, req.Url, client.BuildUrl(s.Request))
+	assert.Equal(t, "http://localhost/pets?tag=a%26b&tag=c+d", req.Url)
+
+	s = g.BuildTestCase(&client.HttpRequest{ Method: "GET", Url: "http://localhost/pets?debug" }, nil)
+	assert.Equal(t, "http://localhost/pets?debug", s.Request.Url)
+	assert.Nil(t, s.Request.Query)
+}
+
+func TestSpecBuilder_GenerateExpectation_Bodiless(t *testing.T) {
+	header := http.Header{}
+	header.Set("Access-Control-Allow-Origin", "*")
+	g, _ := NewSpecBuilder()
+	e := g.GenerateExpectation(&client.HttpResponse{ StatusCode: 204, Header: header, Body: []byte{} })
+	assert.Equal(t, 204, *e.StatusCode.Is.EqualTo.(*int))
+	assert.Equal(t, 1, len(e.Headers.Items))
+	assert.Nil(t, e.Body)
+}
+
+func TestGenerateTitle(t *testing.T) {
+	assert.Equal(t, "GET pets", GenerateTitle(&client.HttpRequest{ Method: "GET", Url: "http://localhost/pets?limit=2" }))
+	assert.Equal(t, "DELETE v1 pets 1", GenerateTitle(&client.HttpRequest{ Method: "DELETE", PDP: "http://localhost:17779", Path: "/v1/pets/1" }))
+
+	titles := map[string]bool{ "GET pets": true, "GET pets (2)": true }
+	assert.Equal(t, "GET pets (3)", uniqueTitle("GET pets", func(title string) bool { return titles[title] }))
+	assert.Equal(t, "POST pets", uniqueTitle("POST pets", func(title string) bool { return titles[title] }))
+}
@@ -2,30 +2,57 @@ package engine
 
 import(
 	"fmt"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/client"
 	"github.com/opwire/opwire-testa/lib/comparison"
+	"github.com/opwire/opwire-testa/lib/jsonpath"
+	"github.com/opwire/opwire-testa/lib/openapi"
 	"github.com/opwire/opwire-testa/lib/sieve"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type SpecHandlerOptions interface {
+	GetPDP() string
+	GetTimeout() string
+	GetTransport() *client.HttpTransportOptions
+	GetOpenAPI() string
 }
 
 type SpecHandler struct {
 	invoker client.HttpInvoker
+	schemas schemaRegistry
+	contract *openapi.Checker
 }
 
 func NewSpecHandler(opts SpecHandlerOptions) (e *SpecHandler, err error) {
 	e = &SpecHandler{}
-	e.invoker, err = client.NewHttpInvoker(nil)
+	invokerOptions := &client.HttpInvokerOptions{}
+	if opts != nil {
+		invokerOptions.PDP = opts.GetPDP()
+		invokerOptions.Timeout = opts.GetTimeout()
+		invokerOptions.Transport = opts.GetTransport()
+	}
+	e.invoker, err = client.NewHttpInvoker(invokerOptions)
 	if err != nil {
 		return nil, err
 	}
+	if opts != nil && len(opts.GetOpenAPI()) > 0 {
+		e.contract, err = openapi.NewChecker(opts.GetOpenAPI())
+		if err != nil {
+			return nil, err
+		}
+	}
 	return e, nil
 }
 
+func (e *SpecHandler) GetContractChecker() *openapi.Checker {
+	return e.contract
+}
+
 func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache) (*ExaminationResult, error) {
 	if testcase == nil {
 		panic(fmt.Errorf("TestCase must not be nil"))
@@ -42,177 +69,333 @@ func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache) (*Exam
 	// start time
 	startTime := time.Now()
 
-	// transform expression
-	req, err := cache.Apply(testcase.Request)
-	if err != nil {
-		panic(err)
+	// transform expression & make the testing request, again while the retry policy requires
+	retry := newRetryPolicy(testcase.Retry)
+	var req *client.HttpRequest
+	var res *client.HttpResponse
+	var errors map[string]error
+	for {
+		result.Attempts++
+		var err error
+		req, err = cache.Apply(testcase.Request)
+		if err != nil {
+			result.Duration = time.Since(startTime)
+			result.Status = "error"
+			result.Errors = map[string]error{
+				"Request": utils.LabelifyError("Invalid request", err),
+			}
+			return result, nil
+		}
+		result.Request = req
+		if _, err := req.GetRawRequest(); err != nil {
+			result.Duration = time.Since(startTime)
+			result.Status = "error"
+			result.Errors = map[string]error{
+				"Request": utils.LabelifyError("Invalid request", err),
+			}
+			return result, nil
+		}
+
+		res, err = e.invoker.Do(req)
+		if err != nil {
+			if retry.next(result.Attempts) {
+				result.LastFailure = utils.LabelifyError("Web Server not available", err)
+				continue
+			}
+			result.Duration = time.Since(startTime)
+			result.Status = "error"
+			result.Errors = map[string]error{
+				"HttpClient": utils.LabelifyError("Web Server not available", err),
+			}
+			return result, err
+		}
+		result.Response = res
+
+		// matching with expectation
+		errors = e.verifyExpectation(testcase, testcase.Expectation, res)
+		if retry == nil {
+			break
+		}
+		failures := errors
+		if retry.until != nil {
+			failures = e.verifyExpectation(testcase, retry.until, res)
+		}
+		if len(failures) == 0 {
+			// the failures of the previous attempts are not reported for a passing attempt
+			result.LastFailure = nil
+			break
+		}
+		result.LastFailure = combineErrorMap(fmt.Sprintf("Attempt #%d failed", result.Attempts), failures)
+		if !retry.next(result.Attempts) {
+			if retry.until != nil {
+				errors["Retry/Until"] = combineErrorMap(fmt.Sprintf("The until expectation is not satisfied after %d attempt(s)", result.Attempts), failures)
+			}
+			break
+		}
 	}
 
-	// make the testing request
-	res, err := e.invoker.Do(req)
-	if err != nil {
-		result.Duration = time.Since(startTime)
+	// verify the request & response against the API contract
+	if e.contract != nil {
+		if lowReq, err := req.GetRawRequest(); err == nil {
+			for key, err := range e.contract.Verify(lowReq, res.StatusCode, res.Header, res.Body) {
+				errors[key] = err
+			}
+		}
+	}
+	result.Errors = errors
+
+	if len(errors) == 0 {
+		result.Status = "ok"
+	} else {
 		result.Status = "error"
-		result.Errors = map[string]error{
-			"HttpClient": utils.LabelifyError("Web Server not available", err),
+	}
+
+	// cache HttpResponse
+	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
+		_, err := cache.Store(testcase.Capture.StoreID, res)
+		if err != nil {
+			errors["Capture"] = utils.LabelifyError("Cannot store the response", err)
+			result.Status = "error"
 		}
-		return result, err
 	}
-	result.Response = res
 
-	// matching with expectation
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+func (e *SpecHandler) verifyExpectation(testcase *TestCase, expect *Expectation, res *client.HttpResponse) map[string]error {
 	errors := make(map[string]error, 0)
-	expect := testcase.Expectation
-	if expect != nil {
-		_sc := expect.StatusCode
-		if _sc != nil && _sc.Is != nil {
-			if _sc.Is.EqualTo != nil {
-				if eq, _ := comparison.IsEqualTo(res.StatusCode, _sc.Is.EqualTo); !eq {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] is not equal to expected value [%v]", res.StatusCode, _sc.Is.EqualTo)
-				}
+	if expect == nil {
+		return errors
+	}
+	_sc := expect.StatusCode
+	if _sc != nil && _sc.Is != nil {
+		if msgs := _sc.Is.Verify(res.StatusCode); len(msgs) > 0 {
+			errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] %s", res.StatusCode, strings.Join(msgs, ", "))
+		}
+	}
+	_hs := expect.Headers
+	if _hs != nil {
+		if _hs.Total != nil && _hs.Total.Is != nil {
+			headerTotal := len(res.Header)
+			if msgs := _hs.Total.Is.Verify(headerTotal); len(msgs) > 0 {
+				errors["Header/Total"] = fmt.Errorf("Total of headers (%d) %s", headerTotal, strings.Join(msgs, ", "))
 			}
-			if _sc.Is.MemberOf != nil {
-				if !comparison.BelongsTo(res.StatusCode, _sc.Is.MemberOf) {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] must belong to inclusive list %v", res.StatusCode, _sc.Is.MemberOf)
+		}
+		if _hs.Items != nil {
+			for _, item := range _hs.Items {
+				if item.Name == nil || item.Is == nil {
+					continue
 				}
-			}
-			if _sc.Is.NotMemberOf != nil {
-				if comparison.BelongsTo(res.StatusCode, _sc.Is.NotMemberOf) {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] must not belong to exclusive list %v", res.StatusCode, _sc.Is.MemberOf)
+				headerVal := res.Header.Get(*item.Name)
+				if msgs := item.Is.Verify(headerVal); len(msgs) > 0 {
+					errors[fmt.Sprintf("Header[%s]", *item.Name)] = fmt.Errorf("Returned value [%s] %s", headerVal, strings.Join(msgs, ", "))
 				}
 			}
 		}
-		_hs := expect.Headers
-		if _hs != nil {
-			if _hs.Total != nil && _hs.Total.Is != nil {
-				headerTotal := len(res.Header)
-				totalIs := _hs.Total.Is
-				if totalIs.EqualTo != nil {
-					if eq, _ := comparison.IsEqualTo(headerTotal, totalIs.EqualTo); !eq {
-						errors["Header/Total"] = fmt.Errorf("Total of headers (%d) mismatchs with expected number (%v)", headerTotal, totalIs.EqualTo)
-					}
+	}
+	_eb := expect.Body
+	if _eb != nil && _eb.HasFormat != nil {
+		var format string = *_eb.HasFormat
+		if format == utils.BODY_FORMAT_FLAT {
+			var hold bool
+			if _eb.IsEqualTo != nil {
+				hold = true
+				_rb := string(res.Body)
+				if _rb != *_eb.IsEqualTo {
+					errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Response body is mismatched with expected content.\nReceived: %s\nExpected: %s", format, _rb, *_eb.IsEqualTo)
 				}
 			}
-			if _hs.Items != nil {
-				for _, item := range _hs.Items {
-					headerVal := res.Header.Get(*item.Name)
-					if item.Is != nil && item.Is.EqualTo != nil {
-						eq, _ := comparison.IsEqualTo(headerVal, item.Is.EqualTo)
-						if !eq {
-							errors[fmt.Sprintf("Header[%s]", *item.Name)] = fmt.Errorf("Returned value: [%s] is mismatched with expected: [%s]", headerVal, item.Is.EqualTo)
-						}
+			if _eb.MatchWith != nil {
+				hold = true
+				_rb := string(res.Body)
+				if reg, err := regexp.Compile(*_eb.MatchWith); err == nil {
+					if !reg.MatchString(_rb) {
+						errors["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, _rb, *_eb.MatchWith)
 					}
+				} else {
+					errors["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *_eb.MatchWith, err.Error())
 				}
 			}
+			if !hold {
+				errors["Body/Expectation"] = fmt.Errorf("[%s] One of [%s] attributes must be provided", format, "is-equal-to, matches")
+			}
 		}
-		_eb := expect.Body
-		if _eb != nil && _eb.HasFormat != nil {
-			var format string = *_eb.HasFormat
-			if format == utils.BODY_FORMAT_FLAT {
-				var hold bool
-				if _eb.IsEqualTo != nil {
-					hold = true
-					_rb := string(res.Body)
-					if _rb != *_eb.IsEqualTo {
-						errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Response body is mismatched with expected content.\nReceived: %s\nExpected: %s", format, _rb, *_eb.IsEqualTo)
-					}
+		if format == utils.BODY_FORMAT_JSON || format == utils.BODY_FORMAT_YAML {
+			var receivedObj, expectedObj map[string]interface{}
+			next := true
+			if (res.Body == nil) {
+				errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
+				next = false
+			} else if res.IsBodiless() {
+				errors["Body/ReceivedObject"] = bodilessError(format, res)
+				next = false
+			} else if err := utils.Unmarshal(format, res.Body, &receivedObj); err != nil {
+				errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+				next = false
+			}
+			if next && _eb.IsEqualTo != nil {
+				if err := utils.Unmarshal(format, []byte(*_eb.IsEqualTo), &expectedObj); err != nil {
+					errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
+					next = false
 				}
-				if _eb.MatchWith != nil {
-					hold = true
-					_rb := string(res.Body)
-					if reg, err := regexp.Compile(*_eb.MatchWith); err == nil {
-						if !reg.MatchString(_rb) {
-							errors["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, _rb, *_eb.MatchWith)
-						}
-					} else {
-						errors["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *_eb.MatchWith, err.Error())
+				if next {
+					ok, diff := comparison.DeepDiff(expectedObj, receivedObj)
+					if !ok {
+						errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
 					}
 				}
-				if !hold {
-					errors["Body/Expectation"] = fmt.Errorf("[%s] One of [%s] attributes must be provided", format, "is-equal-to, matches")
-				}
 			}
-			if format == utils.BODY_FORMAT_JSON || format == utils.BODY_FORMAT_YAML {
-				var receivedObj, expectedObj map[string]interface{}
-				next := true
-				if (res.Body == nil) {
-					errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
-					next = false
-				} else if err := utils.Unmarshal(format, res.Body, &receivedObj); err != nil {
-					errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+			if next && _eb.Includes != nil {
+				if err := utils.Unmarshal(format, []byte(*_eb.Includes), &expectedObj); err != nil {
+					errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
 					next = false
 				}
-				if next && _eb.IsEqualTo != nil {
-					if err := utils.Unmarshal(format, []byte(*_eb.IsEqualTo), &expectedObj); err != nil {
-						errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
-						next = false
-					}
-					if next {
-						ok, diff := comparison.DeepDiff(expectedObj, receivedObj)
-						if !ok {
-							errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
-						}
+				if next {
+					ok, diff := comparison.IsPartOf(expectedObj, receivedObj)
+					if !ok {
+						errors["Body/Includes"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
 					}
 				}
-				if next && _eb.Includes != nil {
-					if err := utils.Unmarshal(format, []byte(*_eb.Includes), &expectedObj); err != nil {
-						errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
-						next = false
-					}
-					if next {
-						ok, diff := comparison.IsPartOf(expectedObj, receivedObj)
-						if !ok {
-							errors["Body/Includes"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
-						}
+			}
+			if next && len(_eb.Fields) > 0 {
+				eFields := _eb.Fields
+				rFields, _ := utils.Flatten("", receivedObj)
+				for _, eField := range eFields {
+					if eField.Path == nil || eField.Is == nil {
+						continue
 					}
-				}
-				if next && len(_eb.Fields) > 0 {
-					eFields := _eb.Fields
-					rFields, _ := utils.Flatten("", receivedObj)
-					for _, eField := range eFields {
-						if eField.Is != nil && eField.Is.EqualTo != nil {
-							eValue := eField.Is.EqualTo
-							if rValue, ok := rFields[*eField.Path]; ok {
-								if eq, _ := comparison.IsEqualTo(rValue, eValue); !eq {
-									errors["Body/Fields/" + *eField.Path] = fmt.Errorf("Field mismatch expected: %v / received: %v", eValue, rValue)
-								}
-							} else {
-								errors["Body/Fields/" + *eField.Path] = fmt.Errorf("Field not found, expected: %v", eValue)
-							}
-						}
+					rValues, err := selectFields(receivedObj, rFields, *eField.Path)
+					if err != nil {
+						errors["Body/Fields/" + *eField.Path] = err
+					} else if len(rValues) == 0 {
+						errors["Body/Fields/" + *eField.Path] = fmt.Errorf("Field not found, expected: %s", eField.Is)
+					} else if err := verifyFields(eField, rValues); err != nil {
+						errors["Body/Fields/" + *eField.Path] = err
 					}
 				}
 			}
+		}
+	} else if _eb != nil {
+		if _eb.HasFormat == nil && (_eb.IsEqualTo != nil || _eb.Includes != nil) {
+			errors["Body/Expectation"] = fmt.Errorf("Unknown body format, please provides [has-format] value")
+		}
+	}
+	if _eb != nil && _eb.MatchesSchema != nil {
+		if res.IsBodiless() {
+			errors["Body/MatchesSchema"] = bodilessError("schema", res)
 		} else {
-			if _eb.HasFormat == nil && (_eb.IsEqualTo != nil || _eb.Includes != nil) {
-				errors["Body/Expectation"] = fmt.Errorf("Unknown body format, please provides [has-format] value")
-			}
+			e.verifyBodySchema(testcase, _eb, res.Body, errors)
 		}
 	}
-	result.Errors = errors
+	return errors
+}
 
-	if len(errors) == 0 {
-		result.Status = "ok"
-	} else {
-		result.Status = "error"
+func bodilessError(format string, res *client.HttpResponse) error {
+	return fmt.Errorf("[%s] Response [%d] has no body (a response to HEAD, 1xx, 204 or 304)", format, res.StatusCode)
+}
+
+func selectFields(tree map[string]interface{}, fields map[string]interface{}, path string) ([]interface{}, error) {
+	if jsonpath.IsPath(path) {
+		vals, err := jsonpath.Select(tree, path)
+		if err == nil {
+			return vals, nil
+		}
+		// a field name which only looks like a path
+		if val, ok := lookupField(tree, fields, path); ok {
+			return []interface{}{ val }, nil
+		}
+		return nil, err
 	}
+	if val, ok := lookupField(tree, fields, path); ok {
+		return []interface{}{ val }, nil
+	}
+	return make([]interface{}, 0), nil
+}
 
-	// cache HttpResponse
-	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
-		_, err := cache.Store(testcase.Capture.StoreID, res)
-		if err != nil {
-			panic(err)
+func verifyFields(eField MeasureBodyField, rValues []interface{}) error {
+	if len(rValues) == 1 && eField.Quantifier == nil {
+		if msgs := eField.Is.Verify(rValues[0]); len(msgs) > 0 {
+			return fmt.Errorf("Field value [%v] %s", rValues[0], strings.Join(msgs, ", "))
+		}
+		return nil
+	}
+	lines := make([]string, 0)
+	for i, rValue := range rValues {
+		msgs := eField.Is.Verify(rValue)
+		if len(msgs) == 0 {
+			if eField.Quantifier != nil && *eField.Quantifier == QUANTIFIER_ANY {
+				return nil
+			}
+			continue
 		}
+		lines = append(lines, fmt.Sprintf("#%d: Field value [%v] %s", i, rValue, strings.Join(msgs, ", ")))
 	}
+	if len(lines) == 0 {
+		return nil
+	}
+	if eField.Quantifier != nil && *eField.Quantifier == QUANTIFIER_ANY {
+		return fmt.Errorf("None of %d field values matches:\n%s", len(rValues), strings.Join(lines, "\n"))
+	}
+	return fmt.Errorf("%d of %d field values mismatch:\n%s", len(lines), len(rValues), strings.Join(lines, "\n"))
+}
 
-	result.Duration = time.Since(startTime)
-	return result, nil
+func lookupField(tree map[string]interface{}, fields map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := fields[path]; ok {
+		return val, true
+	}
+	// the arrays & objects are not kept in the flattened fields
+	var node interface{} = tree
+	for _, key := range strings.Split(path, ".") {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			val, ok := container[key]
+			if !ok {
+				return nil, false
+			}
+			node = val
+		case map[interface{}]interface{}:
+			val, ok := container[key]
+			if !ok {
+				return nil, false
+			}
+			node = val
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, false
+			}
+			node = container[index]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
 }
 
 type TestSuite struct {
 	TestCases []*TestCase `yaml:"testcases" json:"testcases"`
 	Pending *bool `yaml:"pending,omitempty" json:"pending"`
+	Order *int `yaml:"order,omitempty" json:"order"`
+	DependsOn []string `yaml:"depends-on,omitempty" json:"depends-on"`
 	resultCache *sieve.RestCache
+	sourcePath string
+}
+
+func (r *TestSuite) SetSourcePath(sourcePath string) {
+	r.sourcePath = sourcePath
+	for _, testcase := range r.TestCases {
+		if testcase != nil {
+			testcase.sourcePath = sourcePath
+			if testcase.Request != nil {
+				testcase.Request.SetBaseDir(filepath.Dir(sourcePath))
+			}
+		}
+	}
+}
+
+func (r *TestSuite) GetSourcePath() string {
+	return r.sourcePath
 }
 
 func (r *TestSuite) GetResultCache() (*sieve.RestCache) {
@@ -226,17 +409,32 @@ type TestCase struct {
 	Title string `yaml:"title" json:"title"`
 	Version *string `yaml:"version,omitempty" json:"version"`
 	Request *client.HttpRequest `yaml:"request" json:"request"`
-	Capture *SectionCapture `yaml:"capture" json:"capture"`
-	Expectation *Expectation `yaml:"expectation" json:"expectation"`
+	Capture *SectionCapture `yaml:"capture,omitempty" json:"capture"`
+	Expectation *Expectation `yaml:"expectation,omitempty" json:"expectation"`
+	Retry *SectionRetry `yaml:"retry,omitempty" json:"retry"`
 	Pending *bool `yaml:"pending,omitempty" json:"pending"`
+	Parallel *bool `yaml:"parallel,omitempty" json:"parallel"`
 	Tags []string `yaml:"tags,omitempty" json:"tags"`
 	CreatedTime *string `yaml:"created-time,omitempty" json:"created-time"`
+	sourcePath string
+}
+
+func (r *TestCase) GetSourcePath() string {
+	return r.sourcePath
 }
 
 type SectionCapture struct {
 	StoreID string `yaml:"store-id,omitempty" json:"store-id"`
 }
 
+type SectionRetry struct {
+	MaxAttempts *int `yaml:"max-attempts,omitempty" json:"max-attempts"`
+	Interval *string `yaml:"interval,omitempty" json:"interval"`
+	Backoff *float64 `yaml:"backoff,omitempty" json:"backoff"`
+	MaxInterval *string `yaml:"max-interval,omitempty" json:"max-interval"`
+	Until *Expectation `yaml:"until,omitempty" json:"until"`
+}
+
 type Expectation struct {
 	StatusCode *MeasureStatusCode `yaml:"status-code,omitempty" json:"status-code"`
 	Headers *MeasureHeaders `yaml:"headers,omitempty" json:"headers"`
@@ -266,14 +464,21 @@ type MeasureBody struct {
 	Includes *string `yaml:"includes,omitempty" json:"includes"`
 	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
 	MatchWith *string `yaml:"match-with,omitempty" json:"match-with"`
+	MatchesSchema *SchemaSource `yaml:"matches-schema,omitempty" json:"matches-schema"`
 	Fields []MeasureBodyField `yaml:"fields,omitempty" json:"fields"`
 }
 
 type MeasureBodyField struct {
 	Path *string `yaml:"path,omitempty" json:"path"`
 	Is *ComparisonOperators `yaml:"is,omitempty" json:"is"`
+	Quantifier *string `yaml:"quantifier,omitempty" json:"quantifier"`
 }
 
+const (
+	QUANTIFIER_ALL = "all"
+	QUANTIFIER_ANY = "any"
+)
+
 type ComparisonOperators struct {
 	EqualTo interface{} `yaml:"equal-to,omitempty" json:"equal-to"`
 	NotEqualTo interface{} `yaml:"not-equal-to,omitempty" json:"not-equal-to"`
@@ -283,11 +488,104 @@ type ComparisonOperators struct {
 	GTE interface{} `yaml:"gte,omitempty" json:"gte"`
 	MemberOf []interface{} `yaml:"member-of,omitempty" json:"member-of"`
 	NotMemberOf []interface{} `yaml:"not-member-of,omitempty" json:"not-member-of"`
+	MatchWith *string `yaml:"match-with,omitempty" json:"match-with"`
+	StartsWith *string `yaml:"starts-with,omitempty" json:"starts-with"`
+	EndsWith *string `yaml:"ends-with,omitempty" json:"ends-with"`
+	Contains interface{} `yaml:"contains,omitempty" json:"contains"`
+	IsEmpty *bool `yaml:"is-empty,omitempty" json:"is-empty"`
+	HasType *string `yaml:"has-type,omitempty" json:"has-type"`
+	HasLength *int `yaml:"has-length,omitempty" json:"has-length"`
+}
+
+func (c *ComparisonOperators) Verify(rVal interface{}) []string {
+	msgs := make([]string, 0)
+	for _, op := range c.list() {
+		ok, err := comparison.Evaluate(op.operator, rVal, op.value)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("cannot be verified by [%s]: %s", op.operator, err))
+			continue
+		}
+		if !ok {
+			msgs = append(msgs, op.message)
+		}
+	}
+	return msgs
+}
+
+func (c *ComparisonOperators) String() string {
+	items := make([]string, 0)
+	for _, op := range c.list() {
+		items = append(items, fmt.Sprintf("%s: %v", op.operator, op.value))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+func (c *ComparisonOperators) list() []comparisonOperator {
+	ops := make([]comparisonOperator, 0)
+	if c.EqualTo != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_EQUAL_TO, c.EqualTo, fmt.Sprintf("is not equal to expected value [%v]", c.EqualTo) })
+	}
+	if c.NotEqualTo != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_NOT_EQUAL_TO, c.NotEqualTo, fmt.Sprintf("must not be equal to [%v]", c.NotEqualTo) })
+	}
+	if c.LT != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_LT, c.LT, fmt.Sprintf("must be less than [%v]", c.LT) })
+	}
+	if c.LTE != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_LTE, c.LTE, fmt.Sprintf("must be less than or equal to [%v]", c.LTE) })
+	}
+	if c.GT != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_GT, c.GT, fmt.Sprintf("must be greater than [%v]", c.GT) })
+	}
+	if c.GTE != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_GTE, c.GTE, fmt.Sprintf("must be greater than or equal to [%v]", c.GTE) })
+	}
+	if c.MemberOf != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_MEMBER_OF, c.MemberOf, fmt.Sprintf("must belong to inclusive list %v", c.MemberOf) })
+	}
+	if c.NotMemberOf != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_NOT_MEMBER_OF, c.NotMemberOf, fmt.Sprintf("must not belong to exclusive list %v", c.NotMemberOf) })
+	}
+	if c.MatchWith != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_MATCH_WITH, *c.MatchWith, fmt.Sprintf("does not match with pattern [%v]", *c.MatchWith) })
+	}
+	if c.StartsWith != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_STARTS_WITH, *c.StartsWith, fmt.Sprintf("does not start with [%v]", *c.StartsWith) })
+	}
+	if c.EndsWith != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_ENDS_WITH, *c.EndsWith, fmt.Sprintf("does not end with [%v]", *c.EndsWith) })
+	}
+	if c.Contains != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_CONTAINS, c.Contains, fmt.Sprintf("does not contain [%v]", c.Contains) })
+	}
+	if c.IsEmpty != nil {
+		if *c.IsEmpty {
+			ops = append(ops, comparisonOperator{ comparison.OP_IS_EMPTY, true, "must be empty" })
+		} else {
+			ops = append(ops, comparisonOperator{ comparison.OP_IS_EMPTY, false, "must not be empty" })
+		}
+	}
+	if c.HasType != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_HAS_TYPE, *c.HasType, fmt.Sprintf("must have type [%v]", *c.HasType) })
+	}
+	if c.HasLength != nil {
+		ops = append(ops, comparisonOperator{ comparison.OP_HAS_LENGTH, *c.HasLength, fmt.Sprintf("must have length [%v]", *c.HasLength) })
+	}
+	return ops
+}
+
+type comparisonOperator struct {
+	operator string
+	value interface{}
+	message string
 }
 
 type ExaminationResult struct {
 	Duration time.Duration
 	Errors map[string]error
+	Request *client.HttpRequest
 	Response *client.HttpResponse
 	Status string
+	Attempts int
+	LastFailure error
 }
@@ -0,0 +1,104 @@
+package engine
+
+import(
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/sieve"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestSpecHandler_verifyExpectation_Bodiless(t *testing.T) {
+	e := &SpecHandler{}
+	res := &client.HttpResponse{ StatusCode: 204, Header: http.Header{}, Body: []byte{} }
+
+	errors := e.verifyExpectation(&TestCase{}, &Expectation{
+		Body: &MeasureBody{ HasFormat: utils.RefOfString(utils.BODY_FORMAT_FLAT), IsEqualTo: utils.RefOfString("") },
+	}, res)
+	assert.Equal(t, 0, len(errors))
+
+	errors = e.verifyExpectation(&TestCase{}, &Expectation{
+		Body: &MeasureBody{ HasFormat: utils.RefOfString(utils.BODY_FORMAT_JSON), Includes: utils.RefOfString("{}") },
+	}, res)
+	assert.Equal(t, 1, len(errors))
+	assert.Equal(t, "[json] Response [204] has no body (a response to HEAD, 1xx, 204 or 304)", errors["Body/ReceivedObject"].Error())
+
+	errors = e.verifyExpectation(&TestCase{}, &Expectation{
+		Body: &MeasureBody{ MatchesSchema: &SchemaSource{} },
+	}, res)
+	assert.NotNil(t, errors["Body/MatchesSchema"])
+}
+
+func TestSpecHandler_Examine_Retry(t *testing.T) {
+	var counter int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Write([]byte(`{ "status": "done" }`))
+	}))
+	defer server.Close()
+
+	handler, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+	newTestCase := func(maxAttempts int, until *Expectation) *TestCase {
+		return &TestCase{
+			Title: "Poll the job",
+			Request: &client.HttpRequest{ Method: "GET", Url: server.URL + "/jobs/1" },
+			Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ EqualTo: 200 } } },
+			Retry: &SectionRetry{ MaxAttempts: &maxAttempts, Interval: utils.RefOfString("10ms"), Until: until },
+		}
+	}
+
+	t.Run("Until the expectation passes", func(t *testing.T) {
+		atomic.StoreInt32(&counter, 0)
+		cache, _ := sieve.NewRestCache()
+		result, err := handler.Examine(newTestCase(5, nil), cache)
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", result.Status)
+		assert.Equal(t, 3, result.Attempts)
+		assert.Nil(t, result.LastFailure)
+	})
+
+	t.Run("Attempts are exhausted", func(t *testing.T) {
+		atomic.StoreInt32(&counter, 0)
+		cache, _ := sieve.NewRestCache()
+		result, err := handler.Examine(newTestCase(2, nil), cache)
+		assert.Nil(t, err)
+		assert.Equal(t, "error", result.Status)
+		assert.Equal(t, 2, result.Attempts)
+		assert.NotNil(t, result.Errors["StatusCode"])
+		assert.Contains(t, result.LastFailure.Error(), "Attempt #2 failed")
+	})
+
+	t.Run("Until expectation", func(t *testing.T) {
+		atomic.StoreInt32(&counter, 0)
+		cache, _ := sieve.NewRestCache()
+		until := &Expectation{ StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ NotEqualTo: 202 } } }
+		result, err := handler.Examine(newTestCase(2, until), cache)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.Attempts)
+		assert.Contains(t, result.Errors["Retry/Until"].Error(), "The until expectation is not satisfied after 2 attempt(s)")
+
+		atomic.StoreInt32(&counter, 0)
+		result, err = handler.Examine(newTestCase(3, until), cache)
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", result.Status)
+		assert.Equal(t, 3, result.Attempts)
+	})
+}
+
+func TestSelectFields_DollarKeys(t *testing.T) {
+	tree := map[string]interface{}{ "$schema": "http://json-schema.org/draft-07/schema#", "$id": "user" }
+	fields := map[string]interface{}{ "$schema": "http://json-schema.org/draft-07/schema#", "$id": "user" }
+	vals, err := selectFields(tree, fields, "$schema")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{ "http://json-schema.org/draft-07/schema#" }, vals)
+	vals, err = selectFields(tree, fields, "$id")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{ "user" }, vals)
+}
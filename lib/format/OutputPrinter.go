@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"github.com/gookit/color"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
@@ -24,6 +25,10 @@ func NewOutputPrinter(opts OutputPrinterOptions) (ref *OutputPrinter, err error)
 	return ref, err
 }
 
+func (w *OutputPrinter) Clone() *OutputPrinter {
+	return &OutputPrinter{ options: w.options, writer: w.writer }
+}
+
 func (w *OutputPrinter) GetWriter() io.Writer {
 	if w.writer == nil {
 		return os.Stdout
@@ -153,6 +158,8 @@ func (w *OutputPrinter) IsColorized() bool {
 func (w *OutputPrinter) GetPen(name PenType) Renderer {
 	pen := ColorlessPen
 	if w.IsColorized() {
+		pensMutex.Lock()
+		defer pensMutex.Unlock()
 		if Pens == nil {
 			Pens = make(map[PenType]Renderer, 0)
 		}
@@ -228,3 +235,4 @@ const (
 )
 
 var Pens map[PenType]Renderer
+var pensMutex sync.Mutex
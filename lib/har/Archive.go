@@ -0,0 +1,111 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+// HTTP Archive 1.2 (http://www.softwareishard.com/blog/har-12-spec/), only the used fields
+type Archive struct {
+	Log *Log `json:"log"`
+}
+
+type Log struct {
+	Version string `json:"version"`
+	Entries []*Entry `json:"entries"`
+}
+
+type Entry struct {
+	StartedDateTime string `json:"startedDateTime"`
+	Request *Request `json:"request"`
+	Response *Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL string `json:"url"`
+	Headers []NameValue `json:"headers"`
+	PostData *PostData `json:"postData"`
+}
+
+type Response struct {
+	Status int `json:"status"`
+	Headers []NameValue `json:"headers"`
+	Content *Content `json:"content"`
+}
+
+type NameValue struct {
+	Name string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Params []NameValue `json:"params"`
+	Text string `json:"text"`
+}
+
+type Content struct {
+	MimeType string `json:"mimeType"`
+	Text string `json:"text"`
+	Encoding string `json:"encoding"`
+}
+
+func LoadArchive(filePath string) (*Archive, error) {
+	file, err := storage.GetFs().Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the HAR file [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the HAR file [%s], error: %s", filePath, err)
+	}
+	archive, err := ParseArchive(content)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid HAR file [%s], error: %s", filePath, err)
+	}
+	return archive, nil
+}
+
+func ParseArchive(content []byte) (*Archive, error) {
+	archive := &Archive{}
+	if err := json.Unmarshal(content, archive); err != nil {
+		return nil, err
+	}
+	if archive.Log == nil {
+		return nil, fmt.Errorf("the [log] object is missing")
+	}
+	return archive, nil
+}
+
+// the request body, the form parameters are encoded if the text is absent
+func (r *Request) GetBody() string {
+	if r.PostData == nil {
+		return ""
+	}
+	if len(r.PostData.Text) > 0 || len(r.PostData.Params) == 0 {
+		return r.PostData.Text
+	}
+	values := url.Values{}
+	for _, param := range r.PostData.Params {
+		values.Add(param.Name, param.Value)
+	}
+	return values.Encode()
+}
+
+// the decoded response body
+func (r *Response) GetBody() ([]byte, error) {
+	if r.Content == nil {
+		return []byte{}, nil
+	}
+	if strings.ToLower(r.Content.Encoding) == "base64" {
+		return base64.StdEncoding.DecodeString(r.Content.Text)
+	}
+	return []byte(r.Content.Text), nil
+}
@@ -0,0 +1,509 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/comparison"
+)
+
+// the JSONPath expressions ("$", "$.a", "$['a']") & the JSON Pointers ("/a"), the keys such as "$schema" are field names
+func IsPath(path string) bool {
+	return path == "$" || strings.HasPrefix(path, "$.") || strings.HasPrefix(path, "$[") || strings.HasPrefix(path, "/")
+}
+
+func Select(doc interface{}, path string) ([]interface{}, error) {
+	if strings.HasPrefix(path, "/") {
+		val, err := Resolve(doc, path)
+		if err != nil {
+			return make([]interface{}, 0), nil
+		}
+		return []interface{}{ val }, nil
+	}
+	p, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Select(doc), nil
+}
+
+type Path struct {
+	segments []*segment
+}
+
+func Compile(path string) (*Path, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") && !strings.HasPrefix(path, "@") {
+		return nil, fmt.Errorf("JSONPath [%s] must start with [$]", path)
+	}
+	segments, err := parseSegments(path[1:])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JSONPath [%s]: %s", path, err)
+	}
+	return &Path{ segments: segments }, nil
+}
+
+func (p *Path) IsDefinite() bool {
+	for _, seg := range p.segments {
+		if seg.recursive || seg.kind != SEGMENT_CHILD || len(seg.names) + len(seg.indices) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Path) Select(doc interface{}) []interface{} {
+	nodes := []interface{}{ doc }
+	for _, seg := range p.segments {
+		next := make([]interface{}, 0)
+		for _, node := range nodes {
+			if seg.recursive {
+				for _, item := range descendants(node) {
+					next = append(next, seg.apply(item)...)
+				}
+			} else {
+				next = append(next, seg.apply(node)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+const (
+	SEGMENT_CHILD = iota
+	SEGMENT_WILDCARD
+	SEGMENT_SLICE
+	SEGMENT_FILTER
+)
+
+type segment struct {
+	kind int
+	recursive bool
+	names []string
+	indices []int
+	slice [3]*int
+	filter *filter
+}
+
+func (s *segment) apply(node interface{}) []interface{} {
+	result := make([]interface{}, 0)
+	switch(s.kind) {
+	case SEGMENT_CHILD:
+		for _, name := range s.names {
+			if val, ok := getMember(node, name); ok {
+				result = append(result, val)
+			}
+		}
+		if list, ok := node.([]interface{}); ok {
+			for _, index := range s.indices {
+				if index < 0 {
+					index += len(list)
+				}
+				if index >= 0 && index < len(list) {
+					result = append(result, list[index])
+				}
+			}
+		}
+	case SEGMENT_WILDCARD:
+		result = append(result, children(node)...)
+	case SEGMENT_SLICE:
+		if list, ok := node.([]interface{}); ok {
+			start, end, step := 0, len(list), 1
+			if s.slice[2] != nil && *s.slice[2] != 0 {
+				step = *s.slice[2]
+			}
+			if step < 0 {
+				start, end = len(list) - 1, -len(list) - 1
+			}
+			if s.slice[0] != nil {
+				start = normalizeIndex(*s.slice[0], len(list))
+			}
+			if s.slice[1] != nil {
+				end = normalizeIndex(*s.slice[1], len(list))
+			}
+			for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+				if i >= 0 && i < len(list) {
+					result = append(result, list[i])
+				}
+			}
+		}
+	case SEGMENT_FILTER:
+		for _, item := range children(node) {
+			if s.filter.match(item) {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+func normalizeIndex(index int, length int) int {
+	if index < 0 {
+		return index + length
+	}
+	return index
+}
+
+func parseSegments(expr string) ([]*segment, error) {
+	segments := make([]*segment, 0)
+	for len(expr) > 0 {
+		recursive := false
+		if strings.HasPrefix(expr, "..") {
+			recursive = true
+			expr = expr[2:]
+			if !strings.HasPrefix(expr, "[") {
+				expr = "." + expr
+			}
+		}
+		var seg *segment
+		var err error
+		switch {
+		case strings.HasPrefix(expr, "."):
+			name := expr[1:]
+			end := strings.IndexAny(name, ".[")
+			if end >= 0 {
+				name = name[:end]
+			}
+			if len(name) == 0 {
+				return nil, fmt.Errorf("member name is empty")
+			}
+			expr = expr[1 + len(name):]
+			if name == "*" {
+				seg = &segment{ kind: SEGMENT_WILDCARD }
+			} else {
+				seg = &segment{ kind: SEGMENT_CHILD, names: []string{ name } }
+			}
+		case strings.HasPrefix(expr, "["):
+			end := findClosingBracket(expr)
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing bracket")
+			}
+			seg, err = parseBracket(strings.TrimSpace(expr[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			expr = expr[end + 1:]
+		default:
+			return nil, fmt.Errorf("unexpected [%s]", expr)
+		}
+		seg.recursive = recursive
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+func findClosingBracket(expr string) int {
+	depth := 0
+	var quote rune
+	for i, c := range expr {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracket(content string) (*segment, error) {
+	if content == "*" {
+		return &segment{ kind: SEGMENT_WILDCARD }, nil
+	}
+	if strings.HasPrefix(content, "?") {
+		body := strings.TrimSpace(content[1:])
+		if !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+			return nil, fmt.Errorf("filter [%s] must be enclosed in parentheses", content)
+		}
+		f, err := parseFilter(body[1:len(body)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &segment{ kind: SEGMENT_FILTER, filter: f }, nil
+	}
+	if strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\"") {
+		seg := &segment{ kind: SEGMENT_CHILD, names: make([]string, 0) }
+		for _, item := range splitOutsideQuotesBy(content, ",") {
+			name, ok := unquote(strings.TrimSpace(item))
+			if !ok {
+				return nil, fmt.Errorf("invalid member name [%s]", item)
+			}
+			seg.names = append(seg.names, name)
+		}
+		return seg, nil
+	}
+	if strings.Contains(content, ":") {
+		parts := strings.Split(content, ":")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("invalid slice [%s]", content)
+		}
+		seg := &segment{ kind: SEGMENT_SLICE }
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if len(part) == 0 {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice [%s]", content)
+			}
+			seg.slice[i] = &n
+		}
+		return seg, nil
+	}
+	seg := &segment{ kind: SEGMENT_CHILD, indices: make([]int, 0) }
+	for _, item := range strings.Split(content, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("invalid index [%s]", item)
+		}
+		seg.indices = append(seg.indices, n)
+	}
+	return seg, nil
+}
+
+type filter struct {
+	any [][]*condition
+}
+
+type condition struct {
+	path *Path
+	operator string
+	value interface{}
+	pattern *regexp.Regexp
+}
+
+var FILTER_OPERATORS = []string{ "==", "!=", "<=", ">=", "=~", "<", ">" }
+
+func parseFilter(expr string) (*filter, error) {
+	f := &filter{ any: make([][]*condition, 0) }
+	for _, alt := range splitOutsideQuotesBy(expr, "||") {
+		all := make([]*condition, 0)
+		for _, item := range splitOutsideQuotesBy(alt, "&&") {
+			c, err := parseCondition(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, c)
+		}
+		f.any = append(f.any, all)
+	}
+	return f, nil
+}
+
+func parseCondition(expr string) (*condition, error) {
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("filter condition [%s] must start with [@]", expr)
+	}
+	c := &condition{}
+	left := expr
+	pos, op := -1, ""
+	for _, candidate := range FILTER_OPERATORS {
+		if i := indexOutsideQuotes(expr, candidate); i > 0 && (pos < 0 || i < pos) {
+			pos, op = i, candidate
+		}
+	}
+	if pos > 0 {
+		left = strings.TrimSpace(expr[:pos])
+		c.operator = op
+		right := strings.TrimSpace(expr[pos + len(op):])
+		if op == "=~" {
+			pattern := right
+			if strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") && len(pattern) > 1 {
+				pattern = pattern[1:len(pattern)-1]
+			} else if str, ok := unquote(pattern); ok {
+				pattern = str
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression [%s]", right)
+			}
+			c.pattern = re
+		} else {
+			val, err := parseLiteral(right)
+			if err != nil {
+				return nil, err
+			}
+			c.value = val
+		}
+	}
+	p, err := Compile(left)
+	if err != nil {
+		return nil, err
+	}
+	c.path = p
+	return c, nil
+}
+
+func parseLiteral(text string) (interface{}, error) {
+	if str, ok := unquote(text); ok {
+		return str, nil
+	}
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("invalid literal [%s]", text)
+}
+
+func (f *filter) match(node interface{}) bool {
+	for _, all := range f.any {
+		matched := true
+		for _, c := range all {
+			if !c.match(node) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *condition) match(node interface{}) bool {
+	vals := c.path.Select(node)
+	if len(vals) == 0 {
+		return false
+	}
+	val := vals[0]
+	switch c.operator {
+	case "":
+		return true
+	case "=~":
+		return val != nil && c.pattern.MatchString(fmt.Sprintf("%v", val))
+	case "==":
+		if val == nil || c.value == nil {
+			return val == c.value
+		}
+		ok, _ := comparison.Evaluate(comparison.OP_EQUAL_TO, val, c.value)
+		return ok
+	case "!=":
+		if val == nil || c.value == nil {
+			return val != c.value
+		}
+		ok, _ := comparison.Evaluate(comparison.OP_NOT_EQUAL_TO, val, c.value)
+		return ok
+	}
+	operators := map[string]string{
+		"<": comparison.OP_LT,
+		"<=": comparison.OP_LTE,
+		">": comparison.OP_GT,
+		">=": comparison.OP_GTE,
+	}
+	ok, _ := comparison.Evaluate(operators[c.operator], val, c.value)
+	return ok
+}
+
+func getMember(node interface{}, name string) (interface{}, bool) {
+	switch container := node.(type) {
+	case map[string]interface{}:
+		val, ok := container[name]
+		return val, ok
+	case map[interface{}]interface{}:
+		val, ok := container[name]
+		return val, ok
+	}
+	return nil, false
+}
+
+func children(node interface{}) []interface{} {
+	result := make([]interface{}, 0)
+	switch container := node.(type) {
+	case []interface{}:
+		result = append(result, container...)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(container))
+		for key := range container {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, container[key])
+		}
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(container))
+		index := make(map[string]interface{}, len(container))
+		for key, val := range container {
+			name := fmt.Sprintf("%v", key)
+			keys = append(keys, name)
+			index[name] = val
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, index[key])
+		}
+	}
+	return result
+}
+
+func descendants(node interface{}) []interface{} {
+	result := []interface{}{ node }
+	for _, child := range children(node) {
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
+
+func unquote(text string) (string, bool) {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1:len(text)-1], true
+	}
+	return "", false
+}
+
+func splitOutsideQuotesBy(text string, sep string) []string {
+	parts := make([]string, 0)
+	for {
+		pos := indexOutsideQuotes(text, sep)
+		if pos < 0 {
+			break
+		}
+		parts = append(parts, text[:pos])
+		text = text[pos + len(sep):]
+	}
+	return append(parts, text)
+}
+
+func indexOutsideQuotes(text string, sub string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if strings.HasPrefix(text[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
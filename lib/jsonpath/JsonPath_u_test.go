@@ -0,0 +1,85 @@
+package jsonpath
+
+import(
+	"encoding/json"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+const SAMPLE = `{
+	"total": 3,
+	"meta": { "app.version": "1.0", "a/b": 1, "m~n": 2 },
+	"items": [
+		{ "id": "u1", "name": "Alice", "age": 30, "roles": [ "admin" ] },
+		{ "id": "u2", "name": "Bob", "age": 25, "roles": [] },
+		{ "id": "u3", "name": "Carol", "age": 41, "manager": { "id": "u1" } }
+	]
+}`
+
+func TestIsPath(t *testing.T) {
+	assert.True(t, IsPath("$"))
+	assert.True(t, IsPath("$.items"))
+	assert.True(t, IsPath("$['app.version']"))
+	assert.True(t, IsPath("/items/0"))
+	assert.False(t, IsPath("$schema"))
+	assert.False(t, IsPath("$id"))
+	assert.False(t, IsPath("items.0.id"))
+}
+
+func TestSelect(t *testing.T) {
+	var doc interface{}
+	assert.Nil(t, json.Unmarshal([]byte(SAMPLE), &doc))
+
+	selectOf := func(path string) []interface{} {
+		vals, err := Select(doc, path)
+		assert.Nil(t, err)
+		return vals
+	}
+
+	t.Run("Children & indices", func(t *testing.T) {
+		assert.Equal(t, []interface{}{ 3.0 }, selectOf("$.total"))
+		assert.Equal(t, []interface{}{ "u2" }, selectOf("$.items[1].id"))
+		assert.Equal(t, []interface{}{ "u3" }, selectOf("$.items[-1].id"))
+		assert.Equal(t, []interface{}{ "1.0" }, selectOf("$.meta['app.version']"))
+		assert.Equal(t, []interface{}{ "Alice", "Bob" }, selectOf("$.items[0,1].name"))
+		assert.Equal(t, []interface{}{}, selectOf("$.items[5].id"))
+		assert.Equal(t, []interface{}{}, selectOf("$.unknown"))
+	})
+
+	t.Run("Wildcards, slices & recursive descent", func(t *testing.T) {
+		assert.Equal(t, []interface{}{ "u1", "u2", "u3" }, selectOf("$.items[*].id"))
+		assert.Equal(t, []interface{}{ "u2", "u3" }, selectOf("$.items[1:].id"))
+		assert.Equal(t, []interface{}{ "u1", "u2", "u3", "u1" }, selectOf("$..id"))
+		assert.Equal(t, 3, len(selectOf("$.meta.*")))
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		assert.Equal(t, []interface{}{ "Alice", "Carol" }, selectOf("$.items[?(@.age >= 30)].name"))
+		assert.Equal(t, []interface{}{ "Bob" }, selectOf("$.items[?(@.id == 'u2')].name"))
+		assert.Equal(t, []interface{}{ "Carol" }, selectOf("$.items[?(@.manager)].name"))
+		assert.Equal(t, []interface{}{ "Bob", "Carol" }, selectOf("$.items[?(@.name =~ /^[BC]/)].name"))
+		assert.Equal(t, []interface{}{ "Alice", "Bob" }, selectOf("$.items[?(@.age < 26 || @.id == 'u1')].name"))
+		assert.Equal(t, []interface{}{ "Carol" }, selectOf("$.items[?(@.age > 26 && @.name != 'Alice')].name"))
+	})
+
+	t.Run("JSON Pointer", func(t *testing.T) {
+		assert.Equal(t, []interface{}{ "Bob" }, selectOf("/items/1/name"))
+		assert.Equal(t, []interface{}{ 1.0 }, selectOf("/meta/a~1b"))
+		assert.Equal(t, []interface{}{ 2.0 }, selectOf("/meta/m~0n"))
+		assert.Equal(t, []interface{}{}, selectOf("/items/9"))
+	})
+
+	t.Run("Invalid paths", func(t *testing.T) {
+		for _, path := range []string{ "$.items[", "$.items[?(@.age > )]", "$.items[a]", "items" } {
+			_, err := Select(doc, path)
+			assert.NotNil(t, err, path)
+		}
+	})
+
+	t.Run("Definite paths", func(t *testing.T) {
+		p, _ := Compile("$.items[0].id")
+		assert.True(t, p.IsDefinite())
+		p, _ = Compile("$.items[*].id")
+		assert.False(t, p.IsDefinite())
+	})
+}
@@ -0,0 +1,34 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func Resolve(doc interface{}, pointer string) (interface{}, error) {
+	if len(pointer) == 0 {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON Pointer [%s] must start with [/]", pointer)
+	}
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		if list, ok := node.([]interface{}); ok {
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(list) {
+				return nil, fmt.Errorf("JSON Pointer [%s]: index [%s] is out of range", pointer, token)
+			}
+			node = list[index]
+			continue
+		}
+		val, ok := getMember(node, token)
+		if !ok {
+			return nil, fmt.Errorf("JSON Pointer [%s]: member [%s] not found", pointer, token)
+		}
+		node = val
+	}
+	return node, nil
+}
@@ -0,0 +1,310 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"github.com/opwire/opwire-testa/lib/schema"
+)
+
+type Checker struct {
+	document *Document
+	validators map[string]*schema.Validator
+	exercised map[*Operation]bool
+	mutex sync.Mutex
+}
+
+func NewChecker(filePath string) (*Checker, error) {
+	doc, err := LoadDocument(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewCheckerFor(doc), nil
+}
+
+func NewCheckerFor(doc *Document) *Checker {
+	return &Checker{
+		document: doc,
+		validators: make(map[string]*schema.Validator),
+		exercised: make(map[*Operation]bool),
+	}
+}
+
+func (c *Checker) GetDocument() *Document {
+	return c.document
+}
+
+func (c *Checker) Verify(req *http.Request, statusCode int, header http.Header, body []byte) map[string]error {
+	errors := make(map[string]error)
+	if req == nil || req.URL == nil {
+		return errors
+	}
+
+	op, err := c.document.FindOperation(req.Method, req.URL.Path)
+	if err != nil {
+		errors["Contract/Operation"] = err
+		return errors
+	}
+	c.mutex.Lock()
+	c.exercised[op] = true
+	c.mutex.Unlock()
+
+	c.verifyRequest(op, req, errors)
+
+	code, response := c.document.FindResponse(op, statusCode)
+	if response == nil {
+		errors["Contract/StatusCode"] = fmt.Errorf("Status code [%d] is not declared for the operation [%s]", statusCode, op)
+		return errors
+	}
+
+	// response headers
+	if headers, ok := response["headers"].(map[string]interface{}); ok {
+		for name, item := range headers {
+			h, ok := c.document.Resolve(item).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("Contract/Header[%s]", name)
+			values, found := header[http.CanonicalHeaderKey(name)]
+			if !found || len(values) == 0 {
+				if required, _ := h["required"].(bool); required {
+					errors[key] = fmt.Errorf("Header [%s] is required by the response [%s] of the operation [%s]", name, code, op)
+				}
+				continue
+			}
+			if s, ok := h["schema"]; ok {
+				value := convertParameter(values[0], c.document.Resolve(s))
+				if msgs := c.validate(fmt.Sprintf("%s/%s/header/%s", op, code, name), s, value); len(msgs) > 0 {
+					errors[key] = fmt.Errorf("Header value [%s]: %s", values[0], strings.Join(msgs, "; "))
+				}
+			}
+		}
+	}
+
+	// response body
+	content, ok := response["content"].(map[string]interface{})
+	if !ok || len(content) == 0 || len(body) == 0 {
+		return errors
+	}
+	contentType := header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaKey, media := findMediaType(content, mediaType)
+	if media == nil {
+		errors["Contract/Body"] = fmt.Errorf("Content type [%s] is not declared for the response [%s] of the operation [%s]", contentType, code, op)
+		return errors
+	}
+	s, ok := media["schema"]
+	if !ok || !strings.Contains(mediaType, "json") {
+		return errors
+	}
+	var receivedObj interface{}
+	if err := json.Unmarshal(body, &receivedObj); err != nil {
+		errors["Contract/Body"] = fmt.Errorf("Invalid JSON response body: %s", err)
+		return errors
+	}
+	for field, msg := range c.validateFields(fmt.Sprintf("%s/%s/%s", op, code, mediaKey), s, receivedObj) {
+		errors["Contract/Body/" + field] = fmt.Errorf("%s", msg)
+	}
+	return errors
+}
+
+func (c *Checker) verifyRequest(op *Operation, req *http.Request, errors map[string]error) {
+	// request parameters
+	pathValues := c.document.FindPathParameters(op, req.URL.Path)
+	query := req.URL.Query()
+	for _, param := range op.Parameters {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		var values []string
+		switch(in) {
+		case "path":
+			if value, ok := pathValues[name]; ok {
+				values = []string{ value }
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = req.Header[http.CanonicalHeaderKey(name)]
+		default:
+			continue
+		}
+		key := fmt.Sprintf("Contract/Request/%s[%s]", strings.Title(in), name)
+		if len(values) == 0 {
+			if required, _ := param["required"].(bool); required {
+				errors[key] = fmt.Errorf("Parameter [%s] in [%s] is required by the operation [%s]", name, in, op)
+			}
+			continue
+		}
+		if s, ok := param["schema"]; ok {
+			value := convertParameter(values[0], c.document.Resolve(s))
+			if msgs := c.validate(fmt.Sprintf("%s/%s/%s", op, in, name), s, value); len(msgs) > 0 {
+				errors[key] = fmt.Errorf("Parameter value [%s]: %s", values[0], strings.Join(msgs, "; "))
+			}
+		}
+	}
+
+	// request body
+	if op.RequestBody == nil {
+		return
+	}
+	body := readRequestBody(req)
+	if len(body) == 0 {
+		if required, _ := op.RequestBody["required"].(bool); required {
+			errors["Contract/Request/Body"] = fmt.Errorf("Request body is required by the operation [%s]", op)
+		}
+		return
+	}
+	content, ok := op.RequestBody["content"].(map[string]interface{})
+	if !ok || len(content) == 0 {
+		return
+	}
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaKey, media := findMediaType(content, mediaType)
+	if media == nil {
+		errors["Contract/Request/Body"] = fmt.Errorf("Content type [%s] is not declared for the request body of the operation [%s]", contentType, op)
+		return
+	}
+	s, ok := media["schema"]
+	if !ok || !strings.Contains(mediaType, "json") {
+		return
+	}
+	var sentObj interface{}
+	if err := json.Unmarshal(body, &sentObj); err != nil {
+		errors["Contract/Request/Body"] = fmt.Errorf("Invalid JSON request body: %s", err)
+		return
+	}
+	for field, msg := range c.validateFields(fmt.Sprintf("%s/request/%s", op, mediaKey), s, sentObj) {
+		errors["Contract/Request/Body/" + field] = fmt.Errorf("%s", msg)
+	}
+}
+
+// the body of a sent request has been consumed, it is read again by GetBody()
+func readRequestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	reader, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+func (c *Checker) GetUnexercisedOperations() []*Operation {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ops := make([]*Operation, 0)
+	for _, op := range c.document.Operations {
+		if !c.exercised[op] {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (c *Checker) validate(key string, s interface{}, value interface{}) []string {
+	msgs := make([]string, 0)
+	for _, msg := range c.validateFields(key, s, value) {
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+func (c *Checker) validateFields(key string, s interface{}, value interface{}) map[string]string {
+	result := make(map[string]string)
+	validator, err := c.getValidator(key, s)
+	if err != nil {
+		result["(schema)"] = err.Error()
+		return result
+	}
+	if value == nil {
+		// the validator does not accept a nil document
+		value = json.RawMessage("null")
+	}
+	res, err := validator.Validate(value)
+	if err != nil {
+		result["(root)"] = err.Error()
+		return result
+	}
+	for _, violation := range res.Errors() {
+		// the "allOf" wrapper of JSONSchema() fails whenever the wrapped schema fails,
+		// the actual violations are reported besides, so the wrapper one is skipped
+		if violation.Type() == "number_all_of" && violation.Field() == "(root)" {
+			continue
+		}
+		field := strings.TrimPrefix(violation.Field(), "(root).")
+		name := field
+		for i := 2; len(result[name]) > 0; i++ {
+			name = fmt.Sprintf("%s#%d", field, i)
+		}
+		result[name] = schema.DescribeError(violation)
+	}
+	return result
+}
+
+func (c *Checker) getValidator(key string, s interface{}) (*schema.Validator, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if validator, ok := c.validators[key]; ok {
+		return validator, nil
+	}
+	validator, err := schema.NewValidator(&schema.ValidatorOptions{ SchemaObject: c.document.JSONSchema(s) })
+	if err != nil {
+		return nil, fmt.Errorf("Invalid schema of [%s], error: %s", key, err)
+	}
+	c.validators[key] = validator
+	return validator, nil
+}
+
+func findMediaType(content map[string]interface{}, mediaType string) (string, map[string]interface{}) {
+	mediaType = strings.ToLower(mediaType)
+	candidates := []string{ mediaType }
+	if pos := strings.Index(mediaType, "/"); pos > 0 {
+		candidates = append(candidates, mediaType[:pos] + "/*")
+	}
+	candidates = append(candidates, "*/*")
+	for _, candidate := range candidates {
+		for key, item := range content {
+			if strings.ToLower(key) == candidate {
+				media, _ := item.(map[string]interface{})
+				if media == nil {
+					media = make(map[string]interface{})
+				}
+				return key, media
+			}
+		}
+	}
+	return "", nil
+}
+
+func convertParameter(value string, s interface{}) interface{} {
+	obj, _ := s.(map[string]interface{})
+	switch obj["type"] {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -0,0 +1,177 @@
+package openapi
+
+import(
+	"bytes"
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+var petstore = []byte(`
+openapi: 3.0.1
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: http://localhost:8080/api/v1
+paths:
+  /pets:
+    get:
+      tags: [ pets ]
+      parameters:
+        - { name: limit, in: query, schema: { type: integer, maximum: 100 } }
+      responses:
+        '200':
+          description: list of pets
+          headers:
+            X-Total:
+              required: true
+              schema: { type: integer }
+          content:
+            application/json:
+              schema:
+                type: array
+                items: { $ref: '#/components/schemas/Pet' }
+    post:
+      tags: [ pets ]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: { $ref: '#/components/schemas/Pet' }
+      responses:
+        '201':
+          description: created
+  /pets/{id}:
+    parameters:
+      - { name: id, in: path, required: true, schema: { type: integer } }
+    get:
+      tags: [ pets ]
+      responses:
+        '2XX':
+          $ref: '#/components/responses/PetFound'
+        default:
+          description: error
+  /pets/mine:
+    get:
+      responses:
+        '200':
+          description: my pets
+components:
+  responses:
+    PetFound:
+      description: the pet
+      content:
+        application/json:
+          schema: { $ref: '#/components/schemas/Pet' }
+  schemas:
+    Pet:
+      type: object
+      required: [ id, name ]
+      properties:
+        id: { type: integer }
+        name: { type: string }
+        tag: { type: string, nullable: true }
+`)
+
+func TestParseDocument(t *testing.T) {
+	doc, err := ParseDocument(petstore)
+	assert.Nil(t, err)
+	assert.Equal(t, "Petstore", doc.Title)
+	assert.Equal(t, 4, len(doc.Operations))
+	assert.Equal(t, "GET /pets", doc.Operations[0].String())
+	assert.Equal(t, "POST /pets", doc.Operations[1].String())
+
+	t.Run("Find the operation by the request path", func(t *testing.T) {
+		op, err := doc.FindOperation("get", "/api/v1/pets/12")
+		assert.Nil(t, err)
+		assert.Equal(t, "/pets/{id}", op.Path)
+
+		op, err = doc.FindOperation("GET", "/pets/mine")
+		assert.Nil(t, err)
+		assert.Equal(t, "/pets/mine", op.Path)
+
+		_, err = doc.FindOperation("DELETE", "/pets")
+		assert.Equal(t, "Method [DELETE] is not declared for the path [/pets]", err.Error())
+
+		_, err = doc.FindOperation("GET", "/users")
+		assert.Equal(t, "Path [/users] is not declared in the OpenAPI document", err.Error())
+	})
+
+	t.Run("Swagger 2.0 is not supported", func(t *testing.T) {
+		_, err := ParseDocument([]byte(`swagger: "2.0"`))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestChecker_Verify(t *testing.T) {
+	doc, err := ParseDocument(petstore)
+	assert.Nil(t, err)
+	c := NewCheckerFor(doc)
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+
+	t.Run("Conformant response", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/pets/1", nil)
+		errors := c.Verify(req, 200, header, []byte(`{ "id": 1, "name": "Kitty", "tag": null }`))
+		assert.Equal(t, 0, len(errors))
+	})
+
+	t.Run("Response violates the contract", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/pets", nil)
+		h := http.Header{}
+		h.Set("Content-Type", "application/json")
+		h.Set("X-Total", "two")
+		errors := c.Verify(req, 200, h, []byte(`[ { "id": "1", "name": "Kitty" } ]`))
+		assert.Equal(t, 2, len(errors))
+		assert.Equal(t, "Header value [two]: Invalid type. Expected: integer, given: string", errors["Contract/Header[X-Total]"].Error())
+		assert.NotNil(t, errors["Contract/Body/0.id"])
+		// the "allOf" wrapper of the schema is not reported
+		assert.Nil(t, errors["Contract/Body/(root)"])
+	})
+
+	t.Run("Request violates the contract", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/pets/abc", nil)
+		errors := c.Verify(req, 200, header, []byte(`{ "id": 1, "name": "Kitty" }`))
+		assert.Equal(t, 1, len(errors))
+		assert.Equal(t, "Parameter value [abc]: Invalid type. Expected: integer, given: string", errors["Contract/Request/Path[id]"].Error())
+
+		req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/pets?limit=500", nil)
+		errors = c.Verify(req, 200, http.Header{ "X-Total": []string{ "0" } }, nil)
+		assert.Equal(t, 1, len(errors))
+		assert.NotNil(t, errors["Contract/Request/Query[limit]"])
+	})
+
+	t.Run("Request body violates the contract", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/pets", bytes.NewBufferString(`{ "id": "1" }`))
+		req.Header.Set("Content-Type", "application/json")
+		// the body of a sent request has been consumed
+		req.Body.Close()
+		errors := c.Verify(req, 201, header, nil)
+		assert.Equal(t, 2, len(errors))
+		assert.NotNil(t, errors["Contract/Request/Body/id"])
+		assert.Equal(t, "name is required", errors["Contract/Request/Body/(root)"].Error())
+
+		req, _ = http.NewRequest("POST", "http://localhost:8080/api/v1/pets", nil)
+		errors = c.Verify(req, 201, header, nil)
+		assert.Equal(t, "Request body is required by the operation [POST /pets]", errors["Contract/Request/Body"].Error())
+
+		req, _ = http.NewRequest("POST", "http://localhost:8080/api/v1/pets", bytes.NewBufferString(`id=1`))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		errors = c.Verify(req, 201, header, nil)
+		assert.Equal(t, "Content type [application/x-www-form-urlencoded] is not declared for the request body of the operation [POST /pets]", errors["Contract/Request/Body"].Error())
+	})
+
+	t.Run("Undeclared status code", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/pets", nil)
+		errors := c.Verify(req, 400, header, nil)
+		assert.Equal(t, "Status code [400] is not declared for the operation [POST /pets]", errors["Contract/StatusCode"].Error())
+	})
+
+	t.Run("Unexercised operations", func(t *testing.T) {
+		ops := c.GetUnexercisedOperations()
+		assert.Equal(t, 1, len(ops))
+		assert.Equal(t, "GET /pets/mine", ops[0].String())
+	})
+}
@@ -0,0 +1,314 @@
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"gopkg.in/yaml.v2"
+	"github.com/opwire/opwire-testa/lib/jsonpath"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+var METHODS = []string{ "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE" }
+
+type Document struct {
+	Title string
+	Version string
+	Tags []string
+	Operations []*Operation
+	basePaths []string
+	root map[string]interface{}
+}
+
+type Operation struct {
+	Method string
+	Path string
+	OperationID string
+	Summary string
+	Tags []string
+	Parameters []map[string]interface{}
+	RequestBody map[string]interface{}
+	Responses map[string]map[string]interface{}
+	pattern *regexp.Regexp
+	params []string
+}
+
+func (o *Operation) String() string {
+	return o.Method + " " + o.Path
+}
+
+func LoadDocument(filePath string) (*Document, error) {
+	file, err := storage.GetFs().Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the OpenAPI document [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the OpenAPI document [%s], error: %s", filePath, err)
+	}
+	doc, err := ParseDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid OpenAPI document [%s], error: %s", filePath, err)
+	}
+	return doc, nil
+}
+
+func ParseDocument(content []byte) (*Document, error) {
+	// JSON is a subset of YAML
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	root, ok := utils.NormalizeMaps(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the document must be an object")
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3.x documents are supported")
+	}
+
+	d := &Document{ root: root, Operations: make([]*Operation, 0) }
+	if info, ok := root["info"].(map[string]interface{}); ok {
+		d.Title, _ = info["title"].(string)
+		d.Version = fmt.Sprintf("%v", info["version"])
+	}
+	for _, item := range toList(root["tags"]) {
+		if tag, ok := item.(map[string]interface{}); ok {
+			if name, ok := tag["name"].(string); ok {
+				d.Tags = append(d.Tags, name)
+			}
+		}
+	}
+
+	// the base paths of servers are stripped from request paths
+	d.basePaths = make([]string, 0)
+	for _, item := range toList(root["servers"]) {
+		if server, ok := item.(map[string]interface{}); ok {
+			if serverUrl, ok := server["url"].(string); ok {
+				if u, err := url.Parse(serverUrl); err == nil {
+					if basePath := strings.TrimRight(u.Path, "/"); len(basePath) > 0 {
+						d.basePaths = append(d.basePaths, basePath)
+					}
+				}
+			}
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for path, item := range paths {
+		pathItem, ok := d.Resolve(item).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		commonParams := toList(pathItem["parameters"])
+		for _, method := range METHODS {
+			op, ok := pathItem[strings.ToLower(method)].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			d.Operations = append(d.Operations, d.newOperation(method, path, op, commonParams))
+		}
+	}
+	sort.Slice(d.Operations, func(i, j int) bool {
+		if d.Operations[i].Path != d.Operations[j].Path {
+			return d.Operations[i].Path < d.Operations[j].Path
+		}
+		return utils.Index(METHODS, d.Operations[i].Method) < utils.Index(METHODS, d.Operations[j].Method)
+	})
+	return d, nil
+}
+
+func (d *Document) newOperation(method string, path string, op map[string]interface{}, commonParams []interface{}) *Operation {
+	o := &Operation{ Method: method, Path: path }
+	o.OperationID, _ = op["operationId"].(string)
+	o.Summary, _ = op["summary"].(string)
+	for _, tag := range toList(op["tags"]) {
+		o.Tags = append(o.Tags, fmt.Sprintf("%v", tag))
+	}
+
+	// the parameters of operation override the common ones
+	o.Parameters = make([]map[string]interface{}, 0)
+	seen := make(map[string]bool)
+	for _, item := range append(toList(op["parameters"]), commonParams...) {
+		if param, ok := d.Resolve(item).(map[string]interface{}); ok {
+			key := fmt.Sprintf("%v:%v", param["in"], param["name"])
+			if !seen[key] {
+				seen[key] = true
+				o.Parameters = append(o.Parameters, param)
+			}
+		}
+	}
+	o.RequestBody, _ = d.Resolve(op["requestBody"]).(map[string]interface{})
+
+	o.Responses = make(map[string]map[string]interface{})
+	if responses, ok := op["responses"].(map[string]interface{}); ok {
+		for code, item := range responses {
+			if response, ok := d.Resolve(item).(map[string]interface{}); ok {
+				o.Responses[strings.ToUpper(code)] = response
+			}
+		}
+	}
+
+	o.pattern, o.params = compilePathTemplate(path)
+	return o
+}
+
+var PATH_PARAM_REGEXP = regexp.MustCompile(`\{[^}/]+\}`)
+
+func compilePathTemplate(path string) (*regexp.Regexp, []string) {
+	parts := PATH_PARAM_REGEXP.Split(path, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	names := PATH_PARAM_REGEXP.FindAllString(path, -1)
+	for i := range names {
+		names[i] = strings.Trim(names[i], "{}")
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "([^/]+)") + "/?$"), names
+}
+
+func (d *Document) GetBasePath() string {
+	if len(d.basePaths) > 0 {
+		return d.basePaths[0]
+	}
+	return ""
+}
+
+func (d *Document) Resolve(node interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return node
+		}
+		target, err := jsonpath.Resolve(d.root, strings.TrimPrefix(ref, "#"))
+		if err != nil {
+			return nil
+		}
+		node = target
+	}
+	return node
+}
+
+func (d *Document) FindOperation(method string, path string) (*Operation, error) {
+	candidates := d.candidatePaths(path)
+	var matched *Operation
+	pathFound := false
+	for _, candidate := range candidates {
+		for _, op := range d.Operations {
+			if !op.pattern.MatchString(candidate) {
+				continue
+			}
+			pathFound = true
+			if op.Method == strings.ToUpper(method) && (matched == nil || len(op.params) < len(matched.params)) {
+				matched = op
+			}
+		}
+	}
+	if matched != nil {
+		return matched, nil
+	}
+	if pathFound {
+		return nil, fmt.Errorf("Method [%s] is not declared for the path [%s]", strings.ToUpper(method), path)
+	}
+	return nil, fmt.Errorf("Path [%s] is not declared in the OpenAPI document", path)
+}
+
+// extracts the values of the path parameters of the operation from the request path
+func (d *Document) FindPathParameters(op *Operation, path string) map[string]string {
+	values := make(map[string]string)
+	for _, candidate := range d.candidatePaths(path) {
+		matches := op.pattern.FindStringSubmatch(candidate)
+		if matches == nil {
+			continue
+		}
+		for i, name := range op.params {
+			if value, err := url.PathUnescape(matches[i + 1]); err == nil {
+				values[name] = value
+			} else {
+				values[name] = matches[i + 1]
+			}
+		}
+		break
+	}
+	return values
+}
+
+// the request path, and the ones without the base paths of servers
+func (d *Document) candidatePaths(path string) []string {
+	candidates := []string{ path }
+	for _, basePath := range d.basePaths {
+		if strings.HasPrefix(path, basePath) {
+			candidates = append(candidates, strings.TrimPrefix(path, basePath))
+		}
+	}
+	return candidates
+}
+
+func (d *Document) FindResponse(op *Operation, statusCode int) (string, map[string]interface{}) {
+	code := fmt.Sprintf("%d", statusCode)
+	if response, ok := op.Responses[code]; ok {
+		return code, response
+	}
+	code = fmt.Sprintf("%dXX", statusCode / 100)
+	if response, ok := op.Responses[code]; ok {
+		return code, response
+	}
+	if response, ok := op.Responses["DEFAULT"]; ok {
+		return "default", response
+	}
+	return "", nil
+}
+
+// converts an OpenAPI Schema Object to a JSON Schema which refers to the document components,
+// the schema is wrapped in an "allOf" so that the "#/components/..." references are resolved
+// against the components placed beside it (the sibling keywords of a "$ref" are ignored)
+func (d *Document) JSONSchema(schema interface{}) interface{} {
+	result := map[string]interface{}{
+		"allOf": []interface{}{ convertSchema(schema) },
+	}
+	if components, ok := d.root["components"]; ok {
+		result["components"] = convertSchema(components)
+	}
+	return result
+}
+
+func convertSchema(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = convertSchema(item)
+		}
+		if nullable, ok := m["nullable"].(bool); ok {
+			delete(m, "nullable")
+			if t, ok := m["type"].(string); ok && nullable {
+				m["type"] = []interface{}{ t, "null" }
+			}
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = convertSchema(item)
+		}
+		return list
+	}
+	return node
+}
+
+func toList(node interface{}) []interface{} {
+	if list, ok := node.([]interface{}); ok {
+		return list
+	}
+	return make([]interface{}, 0)
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const MAX_SAMPLE_DEPTH = 8
+
+// generates a value which conforms to the schema, preferring the declared examples
+func (d *Document) Sample(schema interface{}) interface{} {
+	return d.sample(schema, 0)
+}
+
+func (d *Document) sample(node interface{}, depth int) interface{} {
+	s, ok := d.Resolve(node).(map[string]interface{})
+	if !ok || depth > MAX_SAMPLE_DEPTH {
+		return nil
+	}
+	if val, ok := s["example"]; ok {
+		return val
+	}
+	if val, ok := s["default"]; ok {
+		return val
+	}
+	if list := toList(s["enum"]); len(list) > 0 {
+		return list[0]
+	}
+	if list := toList(s["allOf"]); len(list) > 0 {
+		obj := make(map[string]interface{})
+		for _, item := range list {
+			if part, ok := d.sample(item, depth + 1).(map[string]interface{}); ok {
+				for key, val := range part {
+					obj[key] = val
+				}
+			}
+		}
+		return obj
+	}
+	for _, keyword := range []string{ "oneOf", "anyOf" } {
+		if list := toList(s[keyword]); len(list) > 0 {
+			return d.sample(list[0], depth + 1)
+		}
+	}
+
+	schemaType, _ := s["type"].(string)
+	if len(schemaType) == 0 {
+		if _, ok := s["properties"]; ok {
+			schemaType = "object"
+		} else if _, ok := s["items"]; ok {
+			schemaType = "array"
+		}
+	}
+	switch(schemaType) {
+	case "object":
+		obj := make(map[string]interface{})
+		properties, _ := s["properties"].(map[string]interface{})
+		required := toList(s["required"])
+		for name, property := range properties {
+			if len(required) > 0 && !containsValue(required, name) {
+				continue
+			}
+			obj[name] = d.sample(property, depth + 1)
+		}
+		return obj
+	case "array":
+		if items, ok := s["items"]; ok {
+			return []interface{}{ d.sample(items, depth + 1) }
+		}
+		return []interface{}{}
+	case "integer", "number":
+		if val, ok := s["minimum"]; ok {
+			return val
+		}
+		return 1
+	case "boolean":
+		return true
+	case "string":
+		switch(s["format"]) {
+		case "date":
+			return "2019-01-01"
+		case "date-time":
+			return "2019-01-01T00:00:00Z"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "http://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// generates the value of a Parameter Object
+func (d *Document) SampleParameter(param map[string]interface{}) string {
+	val, ok := d.exampleOf(param)
+	if !ok {
+		val = d.Sample(param["schema"])
+	}
+	if list, ok := val.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprintf("%v", item)
+		}
+		return strings.Join(items, ",")
+	}
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// generates the content of a Media Type Object
+func (d *Document) SampleMedia(media map[string]interface{}) interface{} {
+	if val, ok := d.exampleOf(media); ok {
+		return val
+	}
+	return d.Sample(media["schema"])
+}
+
+func (d *Document) exampleOf(obj map[string]interface{}) (interface{}, bool) {
+	if val, ok := obj["example"]; ok {
+		return val, true
+	}
+	examples, _ := obj["examples"].(map[string]interface{})
+	keys := make([]string, 0, len(examples))
+	for key := range examples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if example, ok := d.Resolve(examples[key]).(map[string]interface{}); ok {
+			if val, ok := example["value"]; ok {
+				return val, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// converts an OpenAPI Schema Object to a self-contained JSON Schema (recursive references accept any value)
+func (d *Document) InlineSchema(schema interface{}) interface{} {
+	return convertSchema(d.inline(schema, make(map[string]bool)))
+}
+
+func (d *Document) inline(node interface{}, visiting map[string]bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+			if visiting[ref] {
+				return map[string]interface{}{}
+			}
+			visiting[ref] = true
+			defer delete(visiting, ref)
+			return d.inline(d.Resolve(map[string]interface{}{ "$ref": ref }), visiting)
+		}
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = d.inline(item, visiting)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = d.inline(item, visiting)
+		}
+		return list
+	}
+	return node
+}
+
+func containsValue(list []interface{}, val interface{}) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package openapi
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument_Sample(t *testing.T) {
+	doc, err := ParseDocument(petstore)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{ "id": 1, "name": "string" }, doc.Sample(map[string]interface{}{ "$ref": "#/components/schemas/Pet" }))
+	assert.Equal(t, "2019-01-01", doc.Sample(map[string]interface{}{ "type": "string", "format": "date" }))
+	assert.Equal(t, "cat", doc.Sample(map[string]interface{}{ "type": "string", "enum": []interface{}{ "cat", "dog" } }))
+
+	assert.Equal(t, "3,4", doc.SampleParameter(map[string]interface{}{
+		"name": "ids",
+		"examples": map[string]interface{}{
+			"b": map[string]interface{}{ "value": []interface{}{ 5 } },
+			"a": map[string]interface{}{ "value": []interface{}{ 3, 4 } },
+		},
+	}))
+}
+
+func TestDocument_InlineSchema(t *testing.T) {
+	doc, err := ParseDocument([]byte(`
+openapi: 3.0.0
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        name: { type: string, nullable: true }
+        children:
+          type: array
+          items: { $ref: '#/components/schemas/Node' }
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{ "type": []interface{}{ "string", "null" } },
+			"children": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{},
+			},
+		},
+	}, doc.InlineSchema(map[string]interface{}{ "$ref": "#/components/schemas/Node" }))
+}
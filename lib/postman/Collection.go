@@ -0,0 +1,159 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+const SCHEMA_V2_1 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Postman Collection v2.1 (https://schema.getpostman.com), only the used fields
+type Collection struct {
+	Info *Info `json:"info"`
+	Items []*Item `json:"item"`
+	Auth *Auth `json:"auth,omitempty"`
+	Variables []*Variable `json:"variable,omitempty"`
+}
+
+type Info struct {
+	Name string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// an Item is either a request or a folder of items
+type Item struct {
+	Name string `json:"name"`
+	Items []*Item `json:"item,omitempty"`
+	Request *Request `json:"request,omitempty"`
+	Responses []*Response `json:"response,omitempty"`
+	Events []*Event `json:"event,omitempty"`
+	Auth *Auth `json:"auth,omitempty"`
+}
+
+func (i *Item) IsFolder() bool {
+	return i.Request == nil
+}
+
+type Request struct {
+	Method string `json:"method,omitempty"`
+	Headers []*KeyValue `json:"header,omitempty"`
+	URL *URL `json:"url,omitempty"`
+	Body *Body `json:"body,omitempty"`
+	Auth *Auth `json:"auth,omitempty"`
+}
+
+// the URL is either a string or an object
+type URL struct {
+	Raw string `json:"raw"`
+}
+
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	obj := struct {
+		Raw string `json:"raw"`
+	}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	u.Raw = obj.Raw
+	return nil
+}
+
+type Body struct {
+	Mode string `json:"mode"`
+	Raw string `json:"raw,omitempty"`
+	URLEncoded []*KeyValue `json:"urlencoded,omitempty"`
+	FormData []*KeyValue `json:"formdata,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type KeyValue struct {
+	Key string `json:"key"`
+	Value string `json:"value"`
+	Type string `json:"type,omitempty"`
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+type Response struct {
+	Name string `json:"name"`
+	Code int `json:"code"`
+	Headers []*KeyValue `json:"header,omitempty"`
+	Body string `json:"body,omitempty"`
+}
+
+type Event struct {
+	Listen string `json:"listen"`
+	Script *Script `json:"script"`
+}
+
+type Script struct {
+	Type string `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+type Auth struct {
+	Type string `json:"type"`
+	Bearer []*KeyValue `json:"bearer,omitempty"`
+	Basic []*KeyValue `json:"basic,omitempty"`
+	APIKey []*KeyValue `json:"apikey,omitempty"`
+}
+
+// the value of an attribute (e.g. "token" of "bearer") of the authentication
+func (a *Auth) Get(attrs []*KeyValue, key string) string {
+	for _, attr := range attrs {
+		if attr != nil && attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+type Variable struct {
+	Key string `json:"key"`
+	Value string `json:"value"`
+}
+
+func NewCollection(name string) *Collection {
+	return &Collection{
+		Info: &Info{ Name: name, Schema: SCHEMA_V2_1 },
+		Items: make([]*Item, 0),
+	}
+}
+
+func LoadCollection(filePath string) (*Collection, error) {
+	file, err := storage.GetFs().Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the Postman collection [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the Postman collection [%s], error: %s", filePath, err)
+	}
+	collection, err := ParseCollection(content)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Postman collection [%s], error: %s", filePath, err)
+	}
+	return collection, nil
+}
+
+func ParseCollection(content []byte) (*Collection, error) {
+	collection := &Collection{}
+	if err := json.Unmarshal(content, collection); err != nil {
+		return nil, err
+	}
+	if collection.Info == nil {
+		return nil, fmt.Errorf("the [info] object is missing")
+	}
+	if !strings.Contains(collection.Info.Schema, "/collection/v2.") {
+		return nil, fmt.Errorf("only Postman Collection v2.x is supported")
+	}
+	return collection, nil
+}
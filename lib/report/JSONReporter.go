@@ -0,0 +1,265 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const REPORT_FORMAT_JSON = "json"
+const REPORT_FORMAT_JSONL = "jsonl"
+
+const (
+	EVENT_SUITE_STARTED = "suite-started"
+	EVENT_TESTCASE_FINISHED = "testcase-finished"
+	EVENT_SUITE_FINISHED = "suite-finished"
+	EVENT_SUMMARY = "summary"
+)
+
+type JSONReporter struct {
+	filePath string
+	streaming bool
+	writer io.Writer
+	file *os.File
+	suites []*jsonTestSuite
+}
+
+func NewJSONReporter(filePath string, streaming bool) (*JSONReporter, error) {
+	return &JSONReporter{ filePath: filePath, streaming: streaming }, nil
+}
+
+func (r *JSONReporter) SetWriter(writer io.Writer) {
+	r.writer = writer
+}
+
+func (r *JSONReporter) getWriter() (io.Writer, error) {
+	if r.writer == nil {
+		if len(r.filePath) == 0 {
+			r.writer = os.Stdout
+		} else {
+			file, err := os.Create(r.filePath)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot create the report file [%s], error: %s", r.filePath, err)
+			}
+			r.file = file
+			r.writer = file
+		}
+	}
+	return r.writer, nil
+}
+
+func (r *JSONReporter) StartTestSuite(suite *TestSuiteRecord) error {
+	if !r.streaming {
+		return nil
+	}
+	return r.emit(&jsonEvent{
+		Event: EVENT_SUITE_STARTED,
+		Time: suite.StartTime.Format(time.RFC3339Nano),
+		Suite: &jsonTestSuite{
+			Name: suite.Name,
+			Path: suite.Path,
+			StartTime: suite.StartTime.Format(time.RFC3339Nano),
+		},
+	})
+}
+
+func (r *JSONReporter) FinishTestCase(suite *TestSuiteRecord, testcase *TestCaseRecord) error {
+	if !r.streaming {
+		return nil
+	}
+	return r.emit(&jsonEvent{
+		Event: EVENT_TESTCASE_FINISHED,
+		Time: time.Now().Format(time.RFC3339Nano),
+		SuiteName: suite.Name,
+		TestCase: transformTestCase(testcase),
+	})
+}
+
+func (r *JSONReporter) FinishTestSuite(suite *TestSuiteRecord) error {
+	s := transformTestSuite(suite)
+	if !r.streaming {
+		r.suites = append(r.suites, s)
+		return nil
+	}
+	s.TestCases = nil
+	return r.emit(&jsonEvent{
+		Event: EVENT_SUITE_FINISHED,
+		Time: time.Now().Format(time.RFC3339Nano),
+		Suite: s,
+	})
+}
+
+func (r *JSONReporter) Close(summary *SummaryRecord) error {
+	if r.file != nil {
+		defer r.file.Close()
+	}
+	if r.streaming {
+		return r.emit(&jsonEvent{
+			Event: EVENT_SUMMARY,
+			Time: time.Now().Format(time.RFC3339Nano),
+			Summary: transformSummary(summary),
+		})
+	}
+	w, err := r.getWriter()
+	if err != nil {
+		return err
+	}
+	doc := &jsonDocument{
+		Suites: r.suites,
+		Summary: transformSummary(summary),
+	}
+	if doc.Suites == nil {
+		doc.Suites = make([]*jsonTestSuite, 0)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func (r *JSONReporter) emit(event *jsonEvent) error {
+	w, err := r.getWriter()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(event)
+}
+
+func transformTestSuite(suite *TestSuiteRecord) *jsonTestSuite {
+	s := &jsonTestSuite{
+		Name: suite.Name,
+		Path: suite.Path,
+		StartTime: suite.StartTime.Format(time.RFC3339Nano),
+		Duration: toMilliseconds(suite.Duration),
+		Counter: &jsonCounter{
+			Total: len(suite.TestCases),
+			Pending: suite.Count(STATUS_PENDING),
+			Skipped: suite.Count(STATUS_SKIPPED),
+			Success: suite.Count(STATUS_SUCCESS),
+			Failure: suite.Count(STATUS_FAILURE),
+			Cracked: suite.Count(STATUS_CRACKED),
+		},
+		TestCases: make([]*jsonTestCase, 0, len(suite.TestCases)),
+	}
+	if suite.Error != nil {
+		s.Error = suite.Error.Error()
+	}
+	for _, testcase := range suite.TestCases {
+		s.TestCases = append(s.TestCases, transformTestCase(testcase))
+	}
+	return s
+}
+
+func transformTestCase(testcase *TestCaseRecord) *jsonTestCase {
+	c := &jsonTestCase{
+		Title: testcase.Title,
+		Status: testcase.Status,
+		Reason: testcase.Reason,
+		Tags: testcase.Tags,
+		Duration: toMilliseconds(testcase.Duration),
+		Attempts: testcase.Attempts,
+		Errors: make([]*jsonError, 0),
+		Request: testcase.Request,
+		Response: testcase.Response,
+	}
+	if c.Tags == nil {
+		c.Tags = make([]string, 0)
+	}
+	if testcase.LastFailure != nil {
+		c.LastFailure = testcase.LastFailure.Error()
+	}
+	for _, key := range testcase.GetErrorKeys() {
+		e := &jsonError{ Key: key }
+		if err := testcase.Errors[key]; err != nil {
+			e.Message = err.Error()
+		}
+		c.Errors = append(c.Errors, e)
+	}
+	return c
+}
+
+func transformSummary(summary *SummaryRecord) *jsonSummary {
+	if summary == nil {
+		return nil
+	}
+	return &jsonSummary{
+		StartTime: summary.StartTime.Format(time.RFC3339Nano),
+		Duration: toMilliseconds(summary.Duration),
+		TotalFiles: summary.TotalFiles,
+		InvalidFiles: summary.Invalid,
+		Counter: &jsonCounter{
+			Total: summary.Total(),
+			Pending: summary.Pending,
+			Skipped: summary.Skipped,
+			Success: summary.Success,
+			Failure: summary.Failure,
+			Cracked: summary.Cracked,
+		},
+		UnexercisedOperations: summary.UnexercisedOperations,
+	}
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type jsonDocument struct {
+	Suites []*jsonTestSuite `json:"suites"`
+	Summary *jsonSummary `json:"summary"`
+}
+
+type jsonEvent struct {
+	Event string `json:"event"`
+	Time string `json:"time"`
+	SuiteName string `json:"suite-name,omitempty"`
+	Suite *jsonTestSuite `json:"suite,omitempty"`
+	TestCase *jsonTestCase `json:"testcase,omitempty"`
+	Summary *jsonSummary `json:"summary,omitempty"`
+}
+
+type jsonTestSuite struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	StartTime string `json:"start-time"`
+	Duration float64 `json:"duration-ms"`
+	Counter *jsonCounter `json:"counter,omitempty"`
+	Error string `json:"error,omitempty"`
+	TestCases []*jsonTestCase `json:"testcases,omitempty"`
+}
+
+type jsonTestCase struct {
+	Title string `json:"title"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Tags []string `json:"tags"`
+	Duration float64 `json:"duration-ms"`
+	Attempts int `json:"attempts,omitempty"`
+	LastFailure string `json:"last-failure,omitempty"`
+	Errors []*jsonError `json:"errors"`
+	Request *RequestSummary `json:"request,omitempty"`
+	Response *ResponseSummary `json:"response,omitempty"`
+}
+
+type jsonError struct {
+	Key string `json:"key"`
+	Message string `json:"message"`
+}
+
+type jsonCounter struct {
+	Total int `json:"total"`
+	Pending int `json:"pending"`
+	Skipped int `json:"skipped"`
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+	Cracked int `json:"cracked"`
+}
+
+type jsonSummary struct {
+	StartTime string `json:"start-time"`
+	Duration float64 `json:"duration-ms"`
+	TotalFiles int `json:"total-files"`
+	InvalidFiles int `json:"invalid-files,omitempty"`
+	Counter *jsonCounter `json:"counter"`
+	UnexercisedOperations []string `json:"unexercised-operations,omitempty"`
+}
@@ -0,0 +1,114 @@
+package report
+
+import(
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONReporter(t *testing.T) {
+	suite := &TestSuiteRecord{
+		Name: "tests/users.yml",
+		Path: "/projects/demo/tests/users.yml",
+		StartTime: time.Now(),
+		TestCases: make([]*TestCaseRecord, 0),
+	}
+	testcase := &TestCaseRecord{
+		Title: "update user",
+		Tags: []string{ "users" },
+		Status: STATUS_FAILURE,
+		Duration: 1500 * time.Microsecond,
+		Attempts: 2,
+		LastFailure: fmt.Errorf("Attempt #2 failed"),
+		Errors: map[string]error{
+			"StatusCode": fmt.Errorf("Response StatusCode [500] is not equal to expected value [200]"),
+			"Body/Includes": fmt.Errorf("Body mismatch"),
+		},
+		Request: &RequestSummary{ Method: "PUT", Url: "http://localhost:17779/users/1" },
+		Response: &ResponseSummary{ Status: "500 Internal Server Error", StatusCode: 500 },
+	}
+
+	run := func(reporter *JSONReporter) {
+		assert.Nil(t, reporter.StartTestSuite(suite))
+		suite.TestCases = append(suite.TestCases, testcase)
+		assert.Nil(t, reporter.FinishTestCase(suite, testcase))
+		assert.Nil(t, reporter.FinishTestSuite(suite))
+		assert.Nil(t, reporter.Close(&SummaryRecord{ TotalFiles: 1, Failure: 1 }))
+		suite.TestCases = make([]*TestCaseRecord, 0)
+	}
+
+	t.Run("Streaming events (jsonl)", func(t *testing.T) {
+		reporter, _ := NewJSONReporter("", true)
+		buf := new(bytes.Buffer)
+		reporter.SetWriter(buf)
+		run(reporter)
+
+		events := make([]map[string]interface{}, 0)
+		scanner := bufio.NewScanner(buf)
+		for scanner.Scan() {
+			event := make(map[string]interface{})
+			assert.Nil(t, json.Unmarshal(scanner.Bytes(), &event))
+			events = append(events, event)
+		}
+		assert.Equal(t, 4, len(events))
+		assert.Equal(t, EVENT_SUITE_STARTED, events[0]["event"])
+		assert.Equal(t, EVENT_TESTCASE_FINISHED, events[1]["event"])
+		assert.Equal(t, EVENT_SUITE_FINISHED, events[2]["event"])
+		assert.Equal(t, EVENT_SUMMARY, events[3]["event"])
+
+		assert.Equal(t, "tests/users.yml", events[1]["suite-name"])
+		tc := events[1]["testcase"].(map[string]interface{})
+		assert.Equal(t, "failure", tc["status"])
+		assert.Equal(t, 1.5, tc["duration-ms"])
+		assert.Equal(t, 2.0, tc["attempts"])
+		assert.Equal(t, "Attempt #2 failed", tc["last-failure"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{ "key": "Body/Includes", "message": "Body mismatch" },
+			map[string]interface{}{ "key": "StatusCode", "message": "Response StatusCode [500] is not equal to expected value [200]" },
+		}, tc["errors"])
+		assert.Equal(t, "PUT", tc["request"].(map[string]interface{})["method"])
+		assert.Equal(t, 500.0, tc["response"].(map[string]interface{})["status-code"])
+	})
+
+	t.Run("Single document (json)", func(t *testing.T) {
+		reporter, _ := NewJSONReporter("", false)
+		buf := new(bytes.Buffer)
+		reporter.SetWriter(buf)
+		run(reporter)
+
+		doc := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+		suites := doc["suites"].([]interface{})
+		assert.Equal(t, 1, len(suites))
+		testcases := suites[0].(map[string]interface{})["testcases"].([]interface{})
+		assert.Equal(t, "update user", testcases[0].(map[string]interface{})["title"])
+		counter := doc["summary"].(map[string]interface{})["counter"].(map[string]interface{})
+		assert.Equal(t, 1.0, counter["total"])
+		assert.Equal(t, 1.0, counter["failure"])
+	})
+
+	t.Run("Invalid test suites", func(t *testing.T) {
+		reporter, _ := NewJSONReporter("", false)
+		buf := new(bytes.Buffer)
+		reporter.SetWriter(buf)
+		invalid := &TestSuiteRecord{
+			Name: "tests/broken.yml",
+			StartTime: time.Now(),
+			TestCases: make([]*TestCaseRecord, 0),
+			Error: fmt.Errorf("yaml: line 1: did not find expected node content"),
+		}
+		assert.Nil(t, reporter.StartTestSuite(invalid))
+		assert.Nil(t, reporter.FinishTestSuite(invalid))
+		assert.Nil(t, reporter.Close(&SummaryRecord{ Invalid: 1 }))
+
+		doc := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+		s := doc["suites"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "yaml: line 1: did not find expected node content", s["error"])
+		assert.Equal(t, 1.0, doc["summary"].(map[string]interface{})["invalid-files"])
+	})
+}
@@ -0,0 +1,203 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const REPORT_FORMAT_JUNIT = "junit"
+
+type JUnitReporter struct {
+	filePath string
+	writer io.Writer
+	suites []*TestSuiteRecord
+}
+
+func NewJUnitReporter(filePath string) (*JUnitReporter, error) {
+	if len(filePath) == 0 {
+		return nil, fmt.Errorf("The report file path must be provided for [%s] format", REPORT_FORMAT_JUNIT)
+	}
+	return &JUnitReporter{ filePath: filePath }, nil
+}
+
+func (r *JUnitReporter) SetWriter(writer io.Writer) {
+	r.writer = writer
+}
+
+func (r *JUnitReporter) StartTestSuite(suite *TestSuiteRecord) error {
+	return nil
+}
+
+func (r *JUnitReporter) FinishTestCase(suite *TestSuiteRecord, testcase *TestCaseRecord) error {
+	return nil
+}
+
+func (r *JUnitReporter) FinishTestSuite(suite *TestSuiteRecord) error {
+	r.suites = append(r.suites, suite)
+	return nil
+}
+
+func (r *JUnitReporter) Close(summary *SummaryRecord) error {
+	w := r.writer
+	if w == nil {
+		file, err := os.Create(r.filePath)
+		if err != nil {
+			return fmt.Errorf("Cannot create the report file [%s], error: %s", r.filePath, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	return WriteJUnit(w, r.suites, summary)
+}
+
+func WriteJUnit(w io.Writer, suites []*TestSuiteRecord, summary *SummaryRecord) error {
+	doc := &junitTestSuites{
+		Name: "opwire-testa",
+		TestSuites: make([]junitTestSuite, 0, len(suites)),
+	}
+	if summary != nil {
+		doc.Tests = summary.Total() + summary.Invalid
+		doc.Failures = summary.Failure
+		doc.Errors = summary.Cracked + summary.Invalid
+		doc.Skipped = summary.Pending + summary.Skipped
+		doc.Time = formatSeconds(summary.Duration)
+	}
+	for _, suite := range suites {
+		if suite.Error != nil {
+			doc.TestSuites = append(doc.TestSuites, invalidTestSuite(suite))
+			continue
+		}
+		s := junitTestSuite{
+			Name: suite.Name,
+			Tests: len(suite.TestCases),
+			Failures: suite.Count(STATUS_FAILURE),
+			Errors: suite.Count(STATUS_CRACKED),
+			Skipped: suite.Count(STATUS_PENDING) + suite.Count(STATUS_SKIPPED),
+			Time: formatSeconds(suite.Duration),
+			Timestamp: suite.StartTime.Format("2006-01-02T15:04:05"),
+			TestCases: make([]junitTestCase, 0, len(suite.TestCases)),
+		}
+		for _, testcase := range suite.TestCases {
+			c := junitTestCase{
+				Name: testcase.Title,
+				ClassName: suite.Name,
+				Time: formatSeconds(testcase.Duration),
+			}
+			switch(testcase.Status) {
+			case STATUS_PENDING, STATUS_SKIPPED:
+				message := testcase.Status
+				if len(testcase.Reason) > 0 {
+					message = message + ": " + testcase.Reason
+				}
+				c.Skipped = &junitMessage{ Message: message }
+			case STATUS_FAILURE:
+				c.Failure = &junitMessage{
+					Message: fmt.Sprintf("%d expectation(s) failed", len(testcase.Errors)),
+					Type: STATUS_FAILURE,
+					Content: formatErrors(testcase),
+				}
+			case STATUS_CRACKED:
+				c.Error = &junitMessage{
+					Message: "Testcase has cracked",
+					Type: STATUS_CRACKED,
+					Content: formatErrors(testcase),
+				}
+			}
+			s.TestCases = append(s.TestCases, c)
+		}
+		doc.TestSuites = append(doc.TestSuites, s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// an invalid test suite file is reported as a testsuite with a single errored testcase
+func invalidTestSuite(suite *TestSuiteRecord) junitTestSuite {
+	return junitTestSuite{
+		Name: suite.Name,
+		Tests: 1,
+		Errors: 1,
+		Time: formatSeconds(0),
+		Timestamp: suite.StartTime.Format("2006-01-02T15:04:05"),
+		TestCases: []junitTestCase{
+			{
+				Name: suite.Name,
+				ClassName: suite.Name,
+				Time: formatSeconds(0),
+				Error: &junitMessage{
+					Message: "Test suite is invalid",
+					Type: "invalid",
+					Content: suite.Error.Error(),
+				},
+			},
+		},
+	}
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func formatErrors(testcase *TestCaseRecord) string {
+	lines := make([]string, 0)
+	for _, key := range testcase.GetErrorKeys() {
+		lines = append(lines, "--- " + key)
+		if err := testcase.Errors[key]; err != nil {
+			lines = append(lines, err.Error())
+		}
+	}
+	if testcase.LastFailure != nil {
+		lines = append(lines, "--- Last failure", testcase.LastFailure.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+	Name string `xml:"name,attr"`
+	Tests int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors int `xml:"errors,attr"`
+	Skipped int `xml:"skipped,attr"`
+	Time string `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name string `xml:"name,attr"`
+	Tests int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors int `xml:"errors,attr"`
+	Skipped int `xml:"skipped,attr"`
+	Time string `xml:"time,attr"`
+	Timestamp string `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name string `xml:"name,attr"`
+	ClassName string `xml:"classname,attr"`
+	Time string `xml:"time,attr"`
+	Skipped *junitMessage `xml:"skipped,omitempty"`
+	Failure *junitMessage `xml:"failure,omitempty"`
+	Error *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
@@ -0,0 +1,127 @@
+package report
+
+import(
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	t.Run("Report file path is required", func(t *testing.T) {
+		_, err := NewReporter(REPORT_FORMAT_JUNIT, "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unsupported report format", func(t *testing.T) {
+		_, err := NewReporter("html", "report.html")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Render test suites & testcases", func(t *testing.T) {
+		reporter, err := NewJUnitReporter("report.xml")
+		assert.Nil(t, err)
+
+		buf := new(bytes.Buffer)
+		reporter.SetWriter(buf)
+
+		suite := &TestSuiteRecord{
+			Name: "tests/users.yml",
+			StartTime: time.Date(2019, 5, 1, 10, 20, 30, 0, time.UTC),
+			Duration: 1500 * time.Millisecond,
+			TestCases: []*TestCaseRecord{
+				{
+					Title: "create user",
+					Status: STATUS_SUCCESS,
+					Duration: 250 * time.Millisecond,
+				},
+				{
+					Title: "update user",
+					Status: STATUS_FAILURE,
+					Duration: 500 * time.Millisecond,
+					Errors: map[string]error{
+						"StatusCode": fmt.Errorf("Response StatusCode [500] is not equal to expected value [200]"),
+						"Body/Includes": fmt.Errorf("Body mismatch <&>"),
+					},
+				},
+				{
+					Title: "delete user",
+					Status: STATUS_PENDING,
+				},
+				{
+					Title: "list users",
+					Status: STATUS_SKIPPED,
+					Reason: "tags",
+				},
+				{
+					Title: "search users",
+					Status: STATUS_CRACKED,
+					Errors: map[string]error{
+						"HttpClient": fmt.Errorf("Web Server not available"),
+					},
+					LastFailure: fmt.Errorf("Web Server not available (attempt #1)"),
+				},
+			},
+		}
+		assert.Nil(t, reporter.StartTestSuite(suite))
+		assert.Nil(t, reporter.FinishTestSuite(suite))
+		assert.Nil(t, reporter.Close(&SummaryRecord{
+			Duration: 2 * time.Second,
+			TotalFiles: 1,
+			Pending: 1,
+			Skipped: 1,
+			Success: 1,
+			Failure: 1,
+			Cracked: 1,
+		}))
+
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="opwire-testa" tests="5" failures="1" errors="1" skipped="2" time="2.000">
+  <testsuite name="tests/users.yml" tests="5" failures="1" errors="1" skipped="2" time="1.500" timestamp="2019-05-01T10:20:30">
+    <testcase name="create user" classname="tests/users.yml" time="0.250"></testcase>
+    <testcase name="update user" classname="tests/users.yml" time="0.500">
+      <failure message="2 expectation(s) failed" type="failure">--- Body/Includes&#xA;Body mismatch &lt;&amp;&gt;&#xA;--- StatusCode&#xA;Response StatusCode [500] is not equal to expected value [200]</failure>
+    </testcase>
+    <testcase name="delete user" classname="tests/users.yml" time="0.000">
+      <skipped message="pending"></skipped>
+    </testcase>
+    <testcase name="list users" classname="tests/users.yml" time="0.000">
+      <skipped message="skipped: tags"></skipped>
+    </testcase>
+    <testcase name="search users" classname="tests/users.yml" time="0.000">
+      <error message="Testcase has cracked" type="cracked">--- HttpClient&#xA;Web Server not available&#xA;--- Last failure&#xA;Web Server not available (attempt #1)</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+	})
+
+	t.Run("Render invalid test suites", func(t *testing.T) {
+		reporter, err := NewJUnitReporter("report.xml")
+		assert.Nil(t, err)
+
+		buf := new(bytes.Buffer)
+		reporter.SetWriter(buf)
+
+		suite := &TestSuiteRecord{
+			Name: "tests/broken.yml",
+			StartTime: time.Date(2019, 5, 1, 10, 20, 30, 0, time.UTC),
+			TestCases: make([]*TestCaseRecord, 0),
+			Error: fmt.Errorf("yaml: line 1: did not find expected node content"),
+		}
+		assert.Nil(t, reporter.StartTestSuite(suite))
+		assert.Nil(t, reporter.FinishTestSuite(suite))
+		assert.Nil(t, reporter.Close(&SummaryRecord{ Invalid: 1 }))
+
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="opwire-testa" tests="1" failures="0" errors="1" skipped="0" time="0.000">
+  <testsuite name="tests/broken.yml" tests="1" failures="0" errors="1" skipped="0" time="0.000" timestamp="2019-05-01T10:20:30">
+    <testcase name="tests/broken.yml" classname="tests/broken.yml" time="0.000">
+      <error message="Test suite is invalid" type="invalid">yaml: line 1: did not find expected node content</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+	})
+}
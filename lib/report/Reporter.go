@@ -0,0 +1,105 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	STATUS_PENDING = "pending"
+	STATUS_SKIPPED = "skipped"
+	STATUS_SUCCESS = "success"
+	STATUS_FAILURE = "failure"
+	STATUS_CRACKED = "cracked"
+)
+
+type Reporter interface {
+	StartTestSuite(suite *TestSuiteRecord) error
+	FinishTestCase(suite *TestSuiteRecord, testcase *TestCaseRecord) error
+	FinishTestSuite(suite *TestSuiteRecord) error
+	Close(summary *SummaryRecord) error
+}
+
+func NewReporter(format string, filePath string) (Reporter, error) {
+	switch(format) {
+	case REPORT_FORMAT_JUNIT:
+		return NewJUnitReporter(filePath)
+	case REPORT_FORMAT_JSON:
+		return NewJSONReporter(filePath, false)
+	case REPORT_FORMAT_JSONL:
+		return NewJSONReporter(filePath, true)
+	}
+	return nil, fmt.Errorf("Unsupported report format [%s]", format)
+}
+
+type TestSuiteRecord struct {
+	Name string
+	Path string
+	StartTime time.Time
+	Duration time.Duration
+	TestCases []*TestCaseRecord
+	// the error of a test suite file which cannot be run (e.g. invalid or circular dependencies)
+	Error error
+}
+
+func (s *TestSuiteRecord) Count(status string) int {
+	total := 0
+	for _, testcase := range s.TestCases {
+		if testcase.Status == status {
+			total += 1
+		}
+	}
+	return total
+}
+
+type TestCaseRecord struct {
+	Title string
+	Tags []string
+	Status string
+	Reason string
+	Duration time.Duration
+	Attempts int
+	LastFailure error
+	Errors map[string]error
+	Request *RequestSummary
+	Response *ResponseSummary
+}
+
+func (c *TestCaseRecord) GetErrorKeys() []string {
+	keys := make([]string, 0, len(c.Errors))
+	for key := range c.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type RequestSummary struct {
+	Method string `json:"method"`
+	Url string `json:"url"`
+}
+
+type ResponseSummary struct {
+	Status string `json:"status"`
+	StatusCode int `json:"status-code"`
+	ContentType string `json:"content-type,omitempty"`
+	ContentLength int64 `json:"content-length"`
+}
+
+type SummaryRecord struct {
+	StartTime time.Time
+	Duration time.Duration
+	TotalFiles int
+	Pending int
+	Skipped int
+	Success int
+	Failure int
+	Cracked int
+	Invalid int
+	UnexercisedOperations []string
+}
+
+func (s *SummaryRecord) Total() int {
+	return s.Pending + s.Skipped + s.Success + s.Failure + s.Cracked
+}
@@ -2,15 +2,23 @@ package schema
 
 import (
 	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
 	"github.com/xeipuuv/gojsonschema"
 )
 
 type Validator struct {
-	schemaLoader gojsonschema.JSONLoader
+	schema *gojsonschema.Schema
 }
 
 type ValidatorOptions struct {
 	Schema string
+	SchemaObject interface{}
+	// the URL of the schema object, which is one of the References
+	SchemaURL string
+	// the schema objects by their URLs, the "$ref" of the other files are resolved by them
+	References map[string]interface{}
 }
 
 type ValidationResult = gojsonschema.Result
@@ -20,21 +28,64 @@ func NewValidator(opts *ValidatorOptions) (*Validator, error) {
 	if opts == nil {
 		return nil, fmt.Errorf("NewValidator's options must not be nil")
 	}
-	if len(opts.Schema) == 0 {
+	if len(opts.SchemaURL) > 0 {
+		return newReferenceValidator(opts)
+	}
+	var schemaLoader gojsonschema.JSONLoader
+	if len(opts.Schema) > 0 {
+		schemaLoader = gojsonschema.NewStringLoader(opts.Schema)
+	} else if opts.SchemaObject != nil {
+		schemaLoader = gojsonschema.NewGoLoader(opts.SchemaObject)
+	} else {
 		return nil, fmt.Errorf("Validator's schema must not be empty")
 	}
-	validator := &Validator{}
-	validator.schemaLoader = gojsonschema.NewStringLoader(opts.Schema)
-	return validator, nil
+	schema, err := gojsonschema.NewSchema(schemaLoader)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{ schema: schema }, nil
+}
+
+func newReferenceValidator(opts *ValidatorOptions) (*Validator, error) {
+	if _, ok := opts.References[opts.SchemaURL]; !ok {
+		return nil, fmt.Errorf("The schema [%s] is not one of the references", opts.SchemaURL)
+	}
+	schemaLoader := gojsonschema.NewSchemaLoader()
+	for url, schemaObject := range opts.References {
+		if err := schemaLoader.AddSchema(url, gojsonschema.NewGoLoader(schemaObject)); err != nil {
+			return nil, err
+		}
+	}
+	schema, err := schemaLoader.Compile(gojsonschema.NewReferenceLoader(opts.SchemaURL))
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{ schema: schema }, nil
 }
 
 func (v *Validator) Validate(cfg interface{}) (*ValidationResult, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("The configuration object is nil")
 	}
-	if v.schemaLoader == nil {
+	if v.schema == nil {
 		return nil, fmt.Errorf("Validator is not initialized properly")
 	}
 	documentLoader := gojsonschema.NewGoLoader(cfg)
-	return gojsonschema.Validate(v.schemaLoader, documentLoader)
+	return v.schema.Validate(documentLoader)
+}
+
+func DescribeError(violation ValidationError) string {
+	description := violation.Description()
+	// the numeric limits are rational numbers (e.g. 18/1)
+	for _, val := range violation.Details() {
+		if rat, ok := val.(*big.Rat); ok && rat != nil {
+			text := rat.Num().String()
+			if !rat.IsInt() {
+				f, _ := rat.Float64()
+				text = strconv.FormatFloat(f, 'g', -1, 64)
+			}
+			description = strings.Replace(description, rat.String(), text, -1)
+		}
+	}
+	return description
 }
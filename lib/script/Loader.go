@@ -85,8 +85,7 @@ func (l *Loader) LoadFile(locator *Locator) (*Descriptor) {
 	}
 
 	// validate Test Suite by schema
-	result, err3 := l.validator.Validate(testsuite)
-	if err3 != nil {
+	if err3 := validateTestSuite(l.validator, testsuite); err3 != nil {
 		return &Descriptor{
 			Locator: locator,
 			TestSuite: testsuite,
@@ -94,22 +93,27 @@ func (l *Loader) LoadFile(locator *Locator) (*Descriptor) {
 		}
 	}
 
+	testsuite.SetSourcePath(locator.AbsolutePath)
+
+	return &Descriptor{
+		Locator: locator,
+		TestSuite: testsuite,
+	}
+}
+
+func validateTestSuite(validator *schema.Validator, testsuite *engine.TestSuite) error {
+	result, err := validator.Validate(testsuite)
+	if err != nil {
+		return err
+	}
 	if result != nil && !result.Valid() {
 		errs := make([]string, len(result.Errors()))
 		for i, arg := range result.Errors() {
 			errs[i] = arg.String()
 		}
-		return &Descriptor{
-			Locator: locator,
-			TestSuite: testsuite,
-			Error: utils.CombineErrors("", errs),
-		}
-	}
-
-	return &Descriptor{
-		Locator: locator,
-		TestSuite: testsuite,
+		return utils.CombineErrors("", errs)
 	}
+	return nil
 }
 
 func (l *Loader) ReadDirs(sourceDirs []string, ext string) (locators []*Locator, err error) {
@@ -153,6 +157,8 @@ type Locator struct {
 type Descriptor struct {
 	Locator *Locator
 	TestSuite *engine.TestSuite
+	Dependencies []*Descriptor
+	Warnings []string
 	Error error
 }
 
@@ -174,6 +180,30 @@ const scriptSchema string = `{
 					"type": "boolean"
 				}
 			]
+		},
+		"order": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "integer"
+				}
+			]
+		},
+		"depends-on": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
 		}
 	},
 	"definitions": {
@@ -225,6 +255,16 @@ const scriptSchema string = `{
 						}
 					]
 				},
+				"retry": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/Retry"
+						}
+					]
+				},
 				"pending": {
 					"oneOf": [
 						{
@@ -235,6 +275,16 @@ const scriptSchema string = `{
 						}
 					]
 				},
+				"parallel": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
 				"tags": {
 					"oneOf": [
 						{
@@ -268,7 +318,7 @@ const scriptSchema string = `{
 			"properties": {
 				"method": {
 					"type": "string",
-					"enum": [ "", "GET", "PUT", "POST", "PATCH", "DELETE" ]
+					"pattern": "^(` + utils.HTTP_METHOD_PATTERN + `)?$"
 				},
 				"url": {
 					"type": "string"
@@ -279,23 +329,114 @@ const scriptSchema string = `{
 				"path": {
 					"type": "string"
 				},
+				"query": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"minLength": 1
+									},
+									"value": {
+										"type": "string"
+									}
+								},
+								"required": [ "name" ],
+								"additionalProperties": false
+							}
+						}
+					]
+				},
 				"headers": {
-					"type": "array",
-					"items": {
-						"type": "object",
-						"properties": {
-							"name": {
-								"type": "string"
-							},
-							"value": {
-								"type": "string"
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string"
+									},
+									"value": {
+										"type": "string"
+									}
+								}
 							}
 						}
-					}
+					]
 				},
 				"body": {
 					"type": "string"
 				},
+				"body-file": {
+					"type": "string"
+				},
+				"form": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"minLength": 1
+									},
+									"value": {
+										"type": "string"
+									}
+								},
+								"required": [ "name" ],
+								"additionalProperties": false
+							}
+						}
+					]
+				},
+				"multipart": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"minLength": 1
+									},
+									"value": {
+										"type": "string"
+									},
+									"file": {
+										"type": "string"
+									},
+									"filename": {
+										"type": "string"
+									},
+									"content-type": {
+										"type": "string"
+									}
+								},
+								"required": [ "name" ],
+								"additionalProperties": false
+							}
+						}
+					]
+				},
 				"timeout": {
 					"oneOf": [
 						{
@@ -310,6 +451,66 @@ const scriptSchema string = `{
 			},
 			"additionalProperties": false
 		},
+		"Retry": {
+			"type": "object",
+			"properties": {
+				"max-attempts": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "integer",
+							"minimum": 1
+						}
+					]
+				},
+				"interval": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^` + utils.TIMEOUT_PATTERN + `$"
+						}
+					]
+				},
+				"backoff": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "number",
+							"minimum": 1
+						}
+					]
+				},
+				"max-interval": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"pattern": "^` + utils.TIMEOUT_PATTERN + `$"
+						}
+					]
+				},
+				"until": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/Expectation"
+						}
+					]
+				}
+			},
+			"additionalProperties": false
+		},
 		"Expectation": {
 			"type": "object",
 			"properties": {
@@ -449,6 +650,20 @@ const scriptSchema string = `{
 										}
 									]
 								},
+								"matches-schema": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										},
+										{
+											"type": "object"
+										}
+									]
+								},
 								"fields": {
 									"oneOf": [
 										{
@@ -476,6 +691,17 @@ const scriptSchema string = `{
 																		"$ref": "#/definitions/ComparisonOperators"
 																	}
 																]
+															},
+															"quantifier": {
+																"oneOf": [
+																	{
+																		"type": "null"
+																	},
+																	{
+																		"type": "string",
+																		"enum": [ "all", "any" ]
+																	}
+																]
 															}
 														},
 														"additionalProperties": false
@@ -506,7 +732,14 @@ const scriptSchema string = `{
 					]
 				},
 				"not-equal-to": {
-					"type": "null"
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "integer"
+						}
+					]
 				},
 				"lt": {
 					"oneOf": [
@@ -549,9 +782,50 @@ const scriptSchema string = `{
 					]
 				},
 				"member-of": {
-					"type": "null"
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "integer"
+							}
+						}
+					]
 				},
 				"not-member-of": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "integer"
+							}
+						}
+					]
+				},
+				"match-with": {
+					"type": "null"
+				},
+				"starts-with": {
+					"type": "null"
+				},
+				"ends-with": {
+					"type": "null"
+				},
+				"contains": {
+					"type": "null"
+				},
+				"is-empty": {
+					"type": "null"
+				},
+				"has-type": {
+					"type": "null"
+				},
+				"has-length": {
 					"type": "null"
 				}
 			},
@@ -689,6 +963,85 @@ const scriptSchema string = `{
 							}
 						}
 					]
+				},
+				"match-with": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"starts-with": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string"
+						}
+					]
+				},
+				"ends-with": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string"
+						}
+					]
+				},
+				"contains": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						},
+						{
+							"type": "number"
+						},
+						{
+							"type": "string"
+						}
+					]
+				},
+				"is-empty": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"has-type": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"enum": [ "null", "boolean", "integer", "number", "string", "array", "object" ]
+						}
+					]
+				},
+				"has-length": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "integer",
+							"minimum": 0
+						}
+					]
 				}
 			},
 			"additionalProperties": false
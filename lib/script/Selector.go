@@ -75,7 +75,9 @@ func (r *Selector) IsMatched(testName string) bool {
 
 func (r *Selector) GetTestCases(descriptors map[string]*Descriptor) []*engine.TestCase {
 	testcases := make([]*engine.TestCase, 0)
-	for _, d := range descriptors {
+	// the test suites with circular dependencies are not selected
+	sorted, _ := SortDescriptors(descriptors)
+	for _, d := range sorted {
 		testsuite := d.TestSuite
 		if testsuite != nil {
 			for _, testcase := range testsuite.TestCases {
@@ -0,0 +1,31 @@
+package script
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+func TestSelector_GetTestCases(t *testing.T) {
+	newDescriptor := func(path string, dependsOn ...string) *Descriptor {
+		return &Descriptor{
+			Locator: &Locator{ AbsolutePath: path, RelativePath: path },
+			TestSuite: &engine.TestSuite{
+				DependsOn: dependsOn,
+				TestCases: []*engine.TestCase{ &engine.TestCase{ Title: "Test of " + path } },
+			},
+		}
+	}
+
+	t.Run("Test suites with circular dependencies are not selected", func(t *testing.T) {
+		selector, err := NewSelector(nil)
+		assert.Nil(t, err)
+		testcases := selector.GetTestCases(map[string]*Descriptor{
+			"/tests/a.yml": newDescriptor("/tests/a.yml", "b.yml"),
+			"/tests/b.yml": newDescriptor("/tests/b.yml", "a.yml"),
+			"/tests/c.yml": newDescriptor("/tests/c.yml"),
+		})
+		assert.Equal(t, 1, len(testcases))
+		assert.Equal(t, "Test of /tests/c.yml", testcases[0].Title)
+	})
+}
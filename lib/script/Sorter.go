@@ -0,0 +1,103 @@
+package script
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// returns the copies of descriptors with the resolved dependencies (or the errors), the input is not changed
+func SortDescriptors(descriptors map[string]*Descriptor) (sorted []*Descriptor, rejected []*Descriptor) {
+	sorted = make([]*Descriptor, 0, len(descriptors))
+	rejected = make([]*Descriptor, 0)
+
+	// sort by the declared order, then by the file path
+	items := make([]*Descriptor, 0, len(descriptors))
+	for _, d := range descriptors {
+		if d == nil {
+			continue
+		}
+		item := *d
+		item.Warnings = nil
+		items = append(items, &item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		oi, oj := getOrder(items[i]), getOrder(items[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return items[i].Locator.AbsolutePath < items[j].Locator.AbsolutePath
+	})
+
+	// resolve the dependencies, the missing, unselected or invalid test suites are ignored with a warning
+	index := make(map[string]*Descriptor, len(items))
+	for _, d := range items {
+		index[filepath.Clean(d.Locator.AbsolutePath)] = d
+	}
+	for _, d := range items {
+		d.Dependencies = make([]*Descriptor, 0)
+		if d.TestSuite == nil {
+			continue
+		}
+		for _, dep := range d.TestSuite.DependsOn {
+			depPath := dep
+			if !filepath.IsAbs(depPath) {
+				depPath = filepath.Join(filepath.Dir(d.Locator.AbsolutePath), depPath)
+			}
+			other, ok := index[filepath.Clean(depPath)]
+			if !ok {
+				d.Warnings = append(d.Warnings, fmt.Sprintf("The dependency [%s] is not found, not selected or invalid, it is ignored", dep))
+				continue
+			}
+			if other != d {
+				d.Dependencies = append(d.Dependencies, other)
+			}
+		}
+	}
+
+	// pick the first test suite whose dependencies have been picked
+	done := make(map[*Descriptor]bool, len(items))
+	for len(items) > 0 {
+		picked := -1
+		for i, d := range items {
+			ready := true
+			for _, dep := range d.Dependencies {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				picked = i
+				break
+			}
+		}
+		if picked < 0 {
+			break
+		}
+		done[items[picked]] = true
+		sorted = append(sorted, items[picked])
+		items = append(items[:picked], items[picked+1:]...)
+	}
+
+	// the remaining test suites have circular dependencies
+	for _, d := range items {
+		paths := make([]string, 0)
+		for _, dep := range d.Dependencies {
+			if !done[dep] {
+				paths = append(paths, dep.Locator.RelativePath)
+			}
+		}
+		d.Error = fmt.Errorf("Circular dependency, test suite depends on [%s]", strings.Join(paths, ", "))
+		rejected = append(rejected, d)
+	}
+	return sorted, rejected
+}
+
+func getOrder(d *Descriptor) int {
+	if d.TestSuite != nil && d.TestSuite.Order != nil {
+		return *d.TestSuite.Order
+	}
+	return 0
+}
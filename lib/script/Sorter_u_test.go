@@ -0,0 +1,77 @@
+package script
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+func TestSortDescriptors(t *testing.T) {
+	newDescriptor := func(path string, order *int, dependsOn ...string) *Descriptor {
+		return &Descriptor{
+			Locator: &Locator{ AbsolutePath: path, RelativePath: path },
+			TestSuite: &engine.TestSuite{ Order: order, DependsOn: dependsOn },
+		}
+	}
+	paths := func(descriptors []*Descriptor) []string {
+		result := make([]string, 0)
+		for _, d := range descriptors {
+			result = append(result, d.Locator.AbsolutePath)
+		}
+		return result
+	}
+	first := -1
+
+	t.Run("Sorted by path, the declared order comes first", func(t *testing.T) {
+		sorted, rejected := SortDescriptors(map[string]*Descriptor{
+			"/tests/c.yml": newDescriptor("/tests/c.yml", nil),
+			"/tests/a.yml": newDescriptor("/tests/a.yml", nil),
+			"/tests/z.yml": newDescriptor("/tests/z.yml", &first),
+			"/tests/b.yml": newDescriptor("/tests/b.yml", nil),
+		})
+		assert.Equal(t, []string{ "/tests/z.yml", "/tests/a.yml", "/tests/b.yml", "/tests/c.yml" }, paths(sorted))
+		assert.Equal(t, 0, len(rejected))
+	})
+
+	t.Run("Dependencies are placed before", func(t *testing.T) {
+		sorted, rejected := SortDescriptors(map[string]*Descriptor{
+			"/tests/a.yml": newDescriptor("/tests/a.yml", nil, "setup/login.yml"),
+			"/tests/b.yml": newDescriptor("/tests/b.yml", nil),
+			"/tests/setup/login.yml": newDescriptor("/tests/setup/login.yml", nil, "../b.yml", "../unselected.yml"),
+		})
+		assert.Equal(t, []string{ "/tests/b.yml", "/tests/setup/login.yml", "/tests/a.yml" }, paths(sorted))
+		assert.Equal(t, 0, len(rejected))
+		assert.Equal(t, []string{ "/tests/setup/login.yml" }, paths(sorted[2].Dependencies))
+		assert.Equal(t, []string{ "The dependency [../unselected.yml] is not found, not selected or invalid, it is ignored" }, sorted[1].Warnings)
+		assert.Nil(t, sorted[2].Warnings)
+	})
+
+	t.Run("Unknown dependencies are reported", func(t *testing.T) {
+		descriptors := map[string]*Descriptor{
+			"/tests/a.yml": newDescriptor("/tests/a.yml", nil, "unknown.yml"),
+		}
+		sorted, rejected := SortDescriptors(descriptors)
+		assert.Equal(t, []string{ "/tests/a.yml" }, paths(sorted))
+		assert.Equal(t, 0, len(rejected))
+		assert.Equal(t, []string{ "The dependency [unknown.yml] is not found, not selected or invalid, it is ignored" }, sorted[0].Warnings)
+		// the input descriptors are not changed
+		assert.Nil(t, descriptors["/tests/a.yml"].Warnings)
+	})
+
+	t.Run("Circular dependencies are rejected", func(t *testing.T) {
+		descriptors := map[string]*Descriptor{
+			"/tests/a.yml": newDescriptor("/tests/a.yml", nil, "b.yml"),
+			"/tests/b.yml": newDescriptor("/tests/b.yml", nil, "a.yml"),
+			"/tests/c.yml": newDescriptor("/tests/c.yml", nil),
+		}
+		sorted, rejected := SortDescriptors(descriptors)
+		assert.Equal(t, []string{ "/tests/c.yml" }, paths(sorted))
+		assert.Equal(t, []string{ "/tests/a.yml", "/tests/b.yml" }, paths(rejected))
+		assert.NotNil(t, rejected[0].Error)
+		// the input descriptors are not changed
+		for _, d := range descriptors {
+			assert.Nil(t, d.Error)
+			assert.Nil(t, d.Dependencies)
+		}
+	})
+}
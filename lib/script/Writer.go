@@ -0,0 +1,357 @@
+package script
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+	"gopkg.in/yaml.v2"
+	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/schema"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+type Writer struct {
+	validator *schema.Validator
+	mutex sync.Mutex
+}
+
+func NewWriter() (w *Writer, err error) {
+	w = new(Writer)
+	w.validator, err = schema.NewValidator(&schema.ValidatorOptions{ Schema: scriptSchema })
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// verifies that a testcase with the title can be appended to the test suite file
+func (w *Writer) AssertAppendable(filePath string, title string) error {
+	_, testsuite, err := w.readTestSuite(filePath)
+	if err != nil {
+		return err
+	}
+	return assertUniqueTitle(filePath, testsuite, title)
+}
+
+// appends a testcase to the test suite file (which is created if it does not exist)
+func (w *Writer) AppendTestCase(filePath string, testcase *engine.TestCase) error {
+	if testcase == nil {
+		return fmt.Errorf("The testcase must not be nil")
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	content, testsuite, err := w.readTestSuite(filePath)
+	if err != nil {
+		return err
+	}
+	if err := assertUniqueTitle(filePath, testsuite, testcase.Title); err != nil {
+		return err
+	}
+
+	// the existing content is kept as is (including the comments), the testcase is inserted as text
+	updated, err := insertTestCase(content, testcase)
+	if err == nil {
+		var verified *engine.TestSuite
+		verified, err = parseTestSuite(updated)
+		if err == nil && !isAppendedTo(verified, testsuite, testcase) {
+			err = fmt.Errorf("the testcase is not appended properly")
+		}
+	}
+	if err != nil {
+		// fallback: marshal the whole test suite
+		testsuite.TestCases = append(testsuite.TestCases, testcase)
+		if updated, err = yaml.Marshal(testsuite); err != nil {
+			return fmt.Errorf("Cannot marshal the test suite [%s], error: %s", filePath, err)
+		}
+	}
+
+	verified, err := parseTestSuite(updated)
+	if err != nil {
+		return fmt.Errorf("Cannot append the testcase to [%s], error: %s", filePath, err)
+	}
+	if err := validateTestSuite(w.validator, verified); err != nil {
+		return fmt.Errorf("The test suite [%s] would become invalid:\n%s", filePath, err)
+	}
+	return writeFile(filePath, updated)
+}
+
+// replaces the expectation of the testcase (found by its title) in the test suite file
+func (w *Writer) UpdateExpectation(filePath string, title string, expectation *engine.Expectation) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	content, testsuite, err := w.readTestSuite(filePath)
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, testcase := range testsuite.TestCases {
+		if testcase != nil && testcase.Title == title {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("The testcase [%s] is not found in [%s]", title, filePath)
+	}
+
+	updated, err := replaceExpectation(content, index, expectation)
+	if err == nil {
+		var verified *engine.TestSuite
+		verified, err = parseTestSuite(updated)
+		if err == nil && !isSameExpectation(verified, index, title, expectation) {
+			err = fmt.Errorf("the expectation is not replaced properly")
+		}
+	}
+	if err != nil {
+		// fallback: marshal the whole test suite
+		testsuite.TestCases[index].Expectation = expectation
+		if updated, err = yaml.Marshal(testsuite); err != nil {
+			return fmt.Errorf("Cannot marshal the test suite [%s], error: %s", filePath, err)
+		}
+	}
+
+	verified, err := parseTestSuite(updated)
+	if err != nil {
+		return fmt.Errorf("Cannot update the testcase [%s] of [%s], error: %s", title, filePath, err)
+	}
+	if err := validateTestSuite(w.validator, verified); err != nil {
+		return fmt.Errorf("The test suite [%s] would become invalid:\n%s", filePath, err)
+	}
+	return writeFile(filePath, updated)
+}
+
+func (w *Writer) readTestSuite(filePath string) ([]byte, *engine.TestSuite, error) {
+	fs := storage.GetFs()
+	file, err := fs.Open(filePath)
+	if err != nil {
+		if fs.IsNotExist(err) {
+			return []byte{}, &engine.TestSuite{}, nil
+		}
+		return nil, nil, fmt.Errorf("Cannot open the test suite [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Cannot read the test suite [%s], error: %s", filePath, err)
+	}
+	testsuite, err := parseTestSuite(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid test suite [%s], error: %s", filePath, err)
+	}
+	return content, testsuite, nil
+}
+
+func parseTestSuite(content []byte) (*engine.TestSuite, error) {
+	testsuite := &engine.TestSuite{}
+	if err := yaml.Unmarshal(content, testsuite); err != nil {
+		return nil, err
+	}
+	return testsuite, nil
+}
+
+func writeFile(filePath string, content []byte) error {
+	file, err := storage.GetFs().Create(filePath)
+	if err != nil {
+		return fmt.Errorf("Cannot write the test suite [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
+
+func assertUniqueTitle(filePath string, testsuite *engine.TestSuite, title string) error {
+	for _, testcase := range testsuite.TestCases {
+		if testcase != nil && testcase.Title == title {
+			return fmt.Errorf("The testcase [%s] already exists in [%s]", title, filePath)
+		}
+	}
+	return nil
+}
+
+func isAppendedTo(updated *engine.TestSuite, original *engine.TestSuite, testcase *engine.TestCase) bool {
+	total := len(updated.TestCases)
+	if total != len(original.TestCases) + 1 || updated.TestCases[total - 1] == nil {
+		return false
+	}
+	return updated.TestCases[total - 1].Title == testcase.Title
+}
+
+func isSameExpectation(updated *engine.TestSuite, index int, title string, expectation *engine.Expectation) bool {
+	if index >= len(updated.TestCases) || updated.TestCases[index] == nil || updated.TestCases[index].Title != title {
+		return false
+	}
+	expected, err1 := yaml.Marshal(expectation)
+	actual, err2 := yaml.Marshal(updated.TestCases[index].Expectation)
+	return err1 == nil && err2 == nil && string(expected) == string(actual)
+}
+
+var testcasesKeyRe = regexp.MustCompile(`^testcases:\s*(\[\s*\])?\s*(#.*)?$`)
+var sequenceItemRe = regexp.MustCompile(`^(\s*)-(\s+|$)`)
+
+// the lines of the block of testcases in a test suite file
+type testcasesBlock struct {
+	start int
+	last int
+	indent string
+	items []int
+}
+
+func locateTestCases(lines []string) (*testcasesBlock, error) {
+	b := &testcasesBlock{ start: -1, items: make([]int, 0) }
+	for i, line := range lines {
+		if strings.HasPrefix(line, "testcases:") {
+			b.start = i
+			break
+		}
+	}
+	if b.start < 0 {
+		return b, nil
+	}
+	if !testcasesKeyRe.MatchString(lines[b.start]) {
+		return nil, fmt.Errorf("the testcases are not declared in block style")
+	}
+	b.last = b.start
+	for i := b.start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if isBlankLine(line) {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			break
+		}
+		if m := sequenceItemRe.FindStringSubmatch(line); m != nil {
+			if len(b.items) == 0 {
+				b.indent = m[1]
+			}
+			if m[1] == b.indent {
+				b.items = append(b.items, i)
+			}
+		}
+		b.last = i
+	}
+	return b, nil
+}
+
+// the last line of the testcase at the index
+func (b *testcasesBlock) itemEnd(lines []string, index int) int {
+	end := b.last
+	if index + 1 < len(b.items) {
+		end = b.items[index + 1] - 1
+	}
+	for end > b.items[index] && isBlankLine(lines[end]) {
+		end--
+	}
+	return end
+}
+
+func isBlankLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) == 0 || strings.HasPrefix(trimmed, "#")
+}
+
+func indentLines(lines []string, indent string) []string {
+	block := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) > 0 {
+			line = indent + line
+		}
+		block[i] = line
+	}
+	return block
+}
+
+func marshalLines(obj interface{}) ([]string, error) {
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n"), nil
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+}
+
+func joinLines(parts ...[]string) []byte {
+	lines := make([]string, 0)
+	for _, part := range parts {
+		lines = append(lines, part...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func insertTestCase(content []byte, testcase *engine.TestCase) ([]byte, error) {
+	itemLines, err := marshalLines([]*engine.TestCase{ testcase })
+	if err != nil {
+		return nil, err
+	}
+	lines := splitLines(content)
+	b, err := locateTestCases(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// the test suite has no testcases yet
+	if b.start < 0 {
+		return joinLines(lines, []string{ "testcases:" }, itemLines), nil
+	}
+	if strings.Contains(lines[b.start], "[") {
+		lines[b.start] = "testcases:"
+	}
+	return joinLines(lines[:b.last + 1], indentLines(itemLines, b.indent), lines[b.last + 1:]), nil
+}
+
+func replaceExpectation(content []byte, index int, expectation *engine.Expectation) ([]byte, error) {
+	lines := splitLines(content)
+	b, err := locateTestCases(lines)
+	if err != nil {
+		return nil, err
+	}
+	if b.start < 0 || index >= len(b.items) {
+		return nil, fmt.Errorf("the testcase is not found")
+	}
+	blockLines, err := marshalLines(expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	// the column of the keys of the testcase
+	start := b.items[index]
+	end := b.itemEnd(lines, index)
+	m := sequenceItemRe.FindStringSubmatch(lines[start])
+	column := len(m[0])
+	keyIndent := strings.Repeat(" ", column)
+	newBlock := append([]string{ "expectation:" }, indentLines(blockLines, keyIndent + "  ")...)
+
+	for i := start; i <= end; i++ {
+		line := lines[i]
+		if len(line) < column || !strings.HasPrefix(line[column:], "expectation:") {
+			continue
+		}
+		if i > start && strings.TrimSpace(line[:column]) != "" {
+			continue
+		}
+		// the block ends before the next key of the testcase
+		last := i
+		for j := i + 1; j <= end; j++ {
+			if isBlankLine(lines[j]) {
+				continue
+			}
+			if len(lines[j]) - len(strings.TrimLeft(lines[j], " ")) <= column {
+				break
+			}
+			last = j
+		}
+		newBlock[0] = line[:column] + newBlock[0]
+		return joinLines(lines[:i], newBlock, lines[last + 1:]), nil
+	}
+
+	// the testcase has no expectation yet
+	newBlock[0] = keyIndent + newBlock[0]
+	return joinLines(lines[:end + 1], newBlock, lines[end + 1:]), nil
+}
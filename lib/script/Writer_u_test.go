@@ -0,0 +1,145 @@
+package script
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+func TestWriter_AppendTestCase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-writer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := NewWriter()
+	assert.Nil(t, err)
+
+	newTestCase := func(title string) *engine.TestCase {
+		return &engine.TestCase{
+			Title: title,
+			Request: &client.HttpRequest{ Method: "GET", Url: "http://localhost:17779/users" },
+			Tags: []string{ "snapshot" },
+		}
+	}
+
+	t.Run("The formatting & comments are kept", func(t *testing.T) {
+		filePath := filepath.Join(dir, "users.yml")
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(`# users
+testcases:
+  # the first one
+  - title: First
+    request:
+      method: GET
+
+pending: false # at the end
+`), 0644))
+		assert.Nil(t, w.AppendTestCase(filePath, newTestCase("Second")))
+		content, _ := ioutil.ReadFile(filePath)
+		assert.Equal(t, `# users
+testcases:
+  # the first one
+  - title: First
+    request:
+      method: GET
+  - title: Second
+    request:
+      method: GET
+      url: http://localhost:17779/users
+    tags:
+    - snapshot
+
+pending: false # at the end
+`, string(content))
+
+		err := w.AppendTestCase(filePath, newTestCase("First"))
+		assert.Equal(t, "The testcase [First] already exists in [" + filePath + "]", err.Error())
+		assert.NotNil(t, w.AssertAppendable(filePath, "Second"))
+		assert.Nil(t, w.AssertAppendable(filePath, "Third"))
+	})
+
+	t.Run("Empty list of testcases", func(t *testing.T) {
+		filePath := filepath.Join(dir, "empty.yml")
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte("testcases: []\n"), 0644))
+		assert.Nil(t, w.AppendTestCase(filePath, newTestCase("First")))
+		testsuite, err := parseTestSuite(readContent(t, filePath))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(testsuite.TestCases))
+	})
+
+	t.Run("The test suite file is created", func(t *testing.T) {
+		filePath := filepath.Join(dir, "created.yml")
+		assert.Nil(t, w.AppendTestCase(filePath, newTestCase("First")))
+		assert.Nil(t, w.AppendTestCase(filePath, newTestCase("Second")))
+		testsuite, err := parseTestSuite(readContent(t, filePath))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(testsuite.TestCases))
+		assert.Equal(t, "Second", testsuite.TestCases[1].Title)
+	})
+
+	t.Run("Invalid testcase is refused", func(t *testing.T) {
+		filePath := filepath.Join(dir, "invalid.yml")
+		assert.NotNil(t, w.AppendTestCase(filePath, newTestCase("<Generated testcase>")))
+		_, err := os.Stat(filePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestWriter_UpdateExpectation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-writer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := NewWriter()
+	assert.Nil(t, err)
+
+	filePath := filepath.Join(dir, "users.yml")
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte(`testcases:
+- title: First
+  expectation:
+    status-code:
+      is:
+        equal-to: 200 # ok
+  tags: [ snapshot ] # recorded
+- expectation: null
+  title: Second
+- title: Third
+`), 0644))
+
+	code := 201
+	expectation := &engine.Expectation{
+		StatusCode: &engine.MeasureStatusCode{ Is: &engine.ComparisonOperators{ EqualTo: code } },
+	}
+	assert.Nil(t, w.UpdateExpectation(filePath, "First", expectation))
+	assert.Nil(t, w.UpdateExpectation(filePath, "Second", expectation))
+	assert.Nil(t, w.UpdateExpectation(filePath, "Third", expectation))
+	assert.Equal(t, `testcases:
+- title: First
+  expectation:
+    status-code:
+      is:
+        equal-to: 201
+  tags: [ snapshot ] # recorded
+- expectation:
+    status-code:
+      is:
+        equal-to: 201
+  title: Second
+- title: Third
+  expectation:
+    status-code:
+      is:
+        equal-to: 201
+`, string(readContent(t, filePath)))
+
+	assert.NotNil(t, w.UpdateExpectation(filePath, "Fourth", expectation))
+}
+
+func readContent(t *testing.T, filePath string) []byte {
+	content, err := ioutil.ReadFile(filePath)
+	assert.Nil(t, err)
+	return content
+}
@@ -1,11 +1,14 @@
 package sieve
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/jsonpath"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
@@ -17,6 +20,21 @@ func NewRestCache() (*RestCache, error) {
 
 type RestCache struct {
 	restResult map[string]*RestResult
+	variables map[string]string
+	mutex sync.RWMutex
+}
+
+func (s *RestCache) SetVariables(variables map[string]string) {
+	s.variables = variables
+}
+
+func (s *RestCache) GetVariable(name string) (string, bool) {
+	if s.variables != nil {
+		if val, ok := s.variables[name]; ok {
+			return val, true
+		}
+	}
+	return "", false
 }
 
 func (s *RestCache) Evaluate(text string) string {
@@ -58,6 +76,16 @@ func (s *RestCache) Query(query string) (string, error) {
 		return utils.BLANK, fmt.Errorf("Query[%s] not found", query)
 	}
 
+	if q.Attr == ENV_VAR {
+		if val, ok := s.GetVariable(q.ItemKey); ok {
+			return val, nil
+		}
+		if len(q.Default) > 0 {
+			return q.Default, nil
+		}
+		return utils.BLANK, fmt.Errorf("Env[%s] not found", q.ItemKey)
+	}
+
 	if len(q.TestID) == 0 {
 		return utils.BLANK, fmt.Errorf("TestID must not be empty")
 	}
@@ -111,6 +139,26 @@ func (s *RestCache) Query(query string) (string, error) {
 		if len(q.ItemKey) == 0 {
 			return utils.BLANK, fmt.Errorf("Resp[%s].BodyField's name must be provided", q.TestID)
 		}
+		if jsonpath.IsPath(q.ItemKey) {
+			vals, err := jsonpath.Select(rr.BodyObject, q.ItemKey)
+			if err != nil {
+				// a field name which only looks like a path
+				if val, found := rr.BodyField[q.ItemKey]; found {
+					return fmt.Sprintf("%v", val), nil
+				}
+				return utils.BLANK, err
+			}
+			if len(vals) == 0 {
+				if len(q.Default) > 0 {
+					return q.Default, nil
+				}
+				return utils.BLANK, fmt.Errorf("Resp[%s].BodyField[%s] not found", q.TestID, q.ItemKey)
+			}
+			if len(vals) == 1 {
+				return stringifyValue(vals[0]), nil
+			}
+			return stringifyValue(vals), nil
+		}
 		val, found := rr.BodyField[q.ItemKey]
 		if !found {
 			if len(q.Default) > 0 {
@@ -124,6 +172,8 @@ func (s *RestCache) Query(query string) (string, error) {
 }
 
 func (s *RestCache) Get(testId string) (*RestResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	if rr, ok := s.restResult[testId]; ok {
 		return rr, nil
 	} else {
@@ -168,6 +218,28 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 		}
 	}
 
+	if req.Query != nil {
+		err1 = nil
+		r.Query = make([]client.HttpQueryParam, len(req.Query))
+		for i, q := range req.Query {
+			newQ := q
+			if len(newQ.Value) > 0 {
+				var err2 []string
+				newQ.Value, err2 = s.EvaluateWithExplanation(newQ.Value)
+				if err2 != nil {
+					err1 = append(err1, fmt.Sprintf("Evaluate(req.Query[%d]/%s) failed", i, newQ.Name))
+					err1 = utils.AppendLinesWithIndent(err1, err2, 2)
+				}
+			}
+			r.Query[i] = newQ
+		}
+		if len(err1) > 0 {
+			errs = append(errs, "Evaluate(req.Query) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+		err1 = nil
+	}
+
 	if req.Headers != nil {
 		r.Headers = make([]client.HttpHeader, len(req.Headers))
 		for i, h := range req.Headers {
@@ -199,7 +271,66 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 		}
 	}
 
+	if len(req.BodyFile) > 0 {
+		r.BodyFile, err1 = s.EvaluateWithExplanation(req.BodyFile)
+		if err1 != nil {
+			errs = append(errs, "Evaluate(req.BodyFile) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
+	if req.Form != nil {
+		err1 = nil
+		r.Form = make([]client.HttpFormField, len(req.Form))
+		for i, f := range req.Form {
+			newF := f
+			if len(newF.Value) > 0 {
+				var err2 []string
+				newF.Value, err2 = s.EvaluateWithExplanation(newF.Value)
+				if err2 != nil {
+					err1 = append(err1, fmt.Sprintf("Evaluate(req.Form[%d]/%s) failed", i, newF.Name))
+					err1 = utils.AppendLinesWithIndent(err1, err2, 2)
+				}
+			}
+			r.Form[i] = newF
+		}
+		if len(err1) > 0 {
+			errs = append(errs, "Evaluate(req.Form) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
+	if req.Multipart != nil {
+		err1 = nil
+		r.Multipart = make([]client.HttpFormPart, len(req.Multipart))
+		for i, p := range req.Multipart {
+			newP := p
+			if len(newP.Value) > 0 {
+				var err2 []string
+				newP.Value, err2 = s.EvaluateWithExplanation(newP.Value)
+				if err2 != nil {
+					err1 = append(err1, fmt.Sprintf("Evaluate(req.Multipart[%d]/%s) failed", i, newP.Name))
+					err1 = utils.AppendLinesWithIndent(err1, err2, 2)
+				}
+			}
+			if len(newP.File) > 0 {
+				var err2 []string
+				newP.File, err2 = s.EvaluateWithExplanation(newP.File)
+				if err2 != nil {
+					err1 = append(err1, fmt.Sprintf("Evaluate(req.Multipart[%d]/%s/file) failed", i, newP.Name))
+					err1 = utils.AppendLinesWithIndent(err1, err2, 2)
+				}
+			}
+			r.Multipart[i] = newP
+		}
+		if len(err1) > 0 {
+			errs = append(errs, "Evaluate(req.Multipart) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
 	r.Timeout = req.Timeout
+	r.SetBaseDir(req.GetBaseDir())
 
 	if errs != nil && len(errs) > 0 {
 		return r, utils.BuildMultilineError(errs)
@@ -209,6 +340,8 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 }
 
 func (s *RestCache) Store(testId string, res *client.HttpResponse) (*RestResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	if s.restResult == nil {
 		s.restResult = make(map[string]*RestResult, 0)
 	}
@@ -227,6 +360,40 @@ func (s *RestCache) Store(testId string, res *client.HttpResponse) (*RestResult,
 	return nil, nil
 }
 
+func HasResultReferences(req *client.HttpRequest) bool {
+	texts := []string{ req.Method, req.Url, req.PDP, req.Path, req.Body, req.BodyFile }
+	for _, h := range req.Headers {
+		texts = append(texts, h.Value)
+	}
+	for _, q := range req.Query {
+		texts = append(texts, q.Value)
+	}
+	for _, f := range req.Form {
+		texts = append(texts, f.Value)
+	}
+	for _, p := range req.Multipart {
+		texts = append(texts, p.Value, p.File)
+	}
+	for _, text := range texts {
+		for _, exp := range STEP_VAR_EXPRESSION.FindAllString(text, -1) {
+			if q, _ := Parse(exp); q != nil && q.Attr != ENV_VAR {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringifyValue(val interface{}) string {
+	switch val.(type) {
+	case []interface{}, map[string]interface{}:
+		if data, err := json.Marshal(val); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", val)
+}
+
 func NewRestResult(lowRes *client.HttpResponse) (*RestResult, error) {
 	if lowRes == nil {
 		panic(fmt.Errorf("HttpResponse must not be nil"))
@@ -258,6 +425,7 @@ func NewRestResult(lowRes *client.HttpResponse) (*RestResult, error) {
 	}
 
 	if found {
+		res.BodyObject = obj
 		res.BodyField = make(map[string]interface{})
 		flatten, _ := utils.Flatten("", obj)
 		for key, val := range flatten {
@@ -275,6 +443,7 @@ type RestResult struct {
 	ContentLength int64
 	Body []byte
 	BodyField map[string]interface{}
+	BodyObject map[string]interface{}
 }
 
 type DataType int
@@ -286,6 +455,7 @@ const (
 	RESP_HEADER
 	RESP_BODY
 	RESP_BODY_FIELD
+	ENV_VAR
 )
 
 type Query struct {
@@ -301,7 +471,8 @@ var STEP_RES_STATUS_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND,
 var STEP_RES_STATUS_CODE_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.StatusCode\s*(\:\-([^\}]*))?\s*`))
 var STEP_RES_HEADER_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Header\[([^\]]*)\]\s*(\:\-([^\}]*))?\s*`))
 var STEP_RES_BODY_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Body\s*(\:\-([^\}]*))?\s*`))
-var STEP_RES_BODY_FIELD_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Body\[([^\]]*)\]\s*(\:\-([^\}]*))?\s*`))
+var STEP_RES_BODY_FIELD_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Body\[(.*?)\]\s*(\:\-([^\}]*))?\s*`))
+var STEP_ENV_VAR_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*env\.(` + utils.ENV_VAR_PATTERN + `)\s*(\:\-([^\}]*))?\s*`))
 
 func Parse(query string) (*Query, error) {
 	var q *Query
@@ -325,6 +496,11 @@ func Parse(query string) (*Query, error) {
 	if q != nil {
 		return q, nil
 	}
+	q = extract2(ENV_VAR, STEP_ENV_VAR_REGEXP.FindAllStringSubmatch(query, -1))
+	if q != nil {
+		q.ItemKey, q.TestID = q.TestID, utils.BLANK
+		return q, nil
+	}
 	return nil, nil
 }
 
@@ -0,0 +1,113 @@
+package sieve
+
+import(
+	"os"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestRestCache_Evaluate(t *testing.T) {
+	cache, err := NewRestCache()
+	assert.Nil(t, err)
+
+	t.Run("Environment variables", func(t *testing.T) {
+		cache.SetVariables(map[string]string{
+			"API_HOST": "staging.example.com",
+			"TOKEN": "secret",
+		})
+		os.Setenv("OPWIRE_TESTA_SIEVE_VAR", "from-os")
+		defer os.Unsetenv("OPWIRE_TESTA_SIEVE_VAR")
+
+		assert.Equal(t, "https://staging.example.com/v1", cache.Evaluate("https://${{ env.API_HOST }}/v1"))
+		assert.Equal(t, "Bearer secret", cache.Evaluate("Bearer ${{env.TOKEN}}"))
+		// the OS environment variables are not looked up
+		assert.Equal(t, "${{ env.OPWIRE_TESTA_SIEVE_VAR }}", cache.Evaluate("${{ env.OPWIRE_TESTA_SIEVE_VAR }}"))
+		assert.Equal(t, "fallback", cache.Evaluate("${{ env.UNDEFINED_VAR :- fallback }}"))
+		assert.Equal(t, "${{ env.UNDEFINED_VAR }}", cache.Evaluate("${{ env.UNDEFINED_VAR }}"))
+
+		_, errs := cache.EvaluateWithExplanation("${{ env.UNDEFINED_VAR }}")
+		assert.Equal(t, []string{ "Env[UNDEFINED_VAR] not found" }, errs)
+	})
+}
+
+func TestHasResultReferences(t *testing.T) {
+	assert.False(t, HasResultReferences(&client.HttpRequest{
+		Path: "/v1/users/${{ env.USER_ID }}",
+	}))
+	assert.True(t, HasResultReferences(&client.HttpRequest{
+		Path: "/v1/users/${{ case[create-user].Body[id] }}",
+	}))
+	assert.True(t, HasResultReferences(&client.HttpRequest{
+		Headers: []client.HttpHeader{
+			{ Name: "Authorization", Value: "Bearer ${{case[login].Body[token]}}" },
+		},
+	}))
+	assert.True(t, HasResultReferences(&client.HttpRequest{
+		Multipart: []client.HttpFormPart{
+			{ Name: "photo", File: "${{ case[upload].Body[path] }}" },
+		},
+	}))
+}
+
+func TestRestCache_ApplyMultipart(t *testing.T) {
+	cache, err := NewRestCache()
+	assert.Nil(t, err)
+	cache.SetVariables(map[string]string{ "FIXTURES": "/data/fixtures" })
+
+	req := &client.HttpRequest{
+		Url: "http://localhost/pets",
+		Multipart: []client.HttpFormPart{
+			{ Name: "name", Value: "${{ env.FIXTURES }}" },
+			{ Name: "photo", File: "${{ env.FIXTURES }}/a.png" },
+		},
+	}
+	r, err := cache.Apply(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "/data/fixtures", r.Multipart[0].Value)
+	assert.Equal(t, "/data/fixtures/a.png", r.Multipart[1].File)
+	assert.Equal(t, "${{ env.FIXTURES }}/a.png", req.Multipart[1].File)
+}
+
+func TestRestCache_QueryBodyPaths(t *testing.T) {
+	cache, err := NewRestCache()
+	assert.Nil(t, err)
+	_, err = cache.Store("list-users", &client.HttpResponse{
+		StatusCode: 200,
+		Body: []byte(`{ "items": [ { "id": "u1", "roles": [ "admin" ] }, { "id": "u2" } ], "app.version": "1.0", "$schema": "v1" }`),
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "u2", cache.Evaluate("${{ case[list-users].Body[$.items[1].id] }}"))
+	assert.Equal(t, "u1", cache.Evaluate("${{ case[list-users].Body[/items/0/id] }}"))
+	assert.Equal(t, "1.0", cache.Evaluate("${{ case[list-users].Body[$['app.version']] }}"))
+	assert.Equal(t, `["u1","u2"]`, cache.Evaluate("${{ case[list-users].Body[$.items[*].id] }}"))
+	assert.Equal(t, `["admin"]`, cache.Evaluate("${{ case[list-users].Body[$.items[?(@.id == 'u1')].roles] }}"))
+	assert.Equal(t, "none", cache.Evaluate("${{ case[list-users].Body[$.items[5].id] :- none }}"))
+	assert.Equal(t, "u1", cache.Evaluate("${{ case[list-users].Body[items.0.id] }}"))
+	assert.Equal(t, "v1", cache.Evaluate("${{ case[list-users].Body[$schema] }}"))
+}
+
+func TestRestCache_ApplyQuery(t *testing.T) {
+	cache, err := NewRestCache()
+	assert.Nil(t, err)
+	_, err = cache.Store("login", &client.HttpResponse{
+		StatusCode: 200,
+		Body: []byte(`{ "id": "u1" }`),
+	})
+	assert.Nil(t, err)
+
+	req := &client.HttpRequest{
+		Url: "http://localhost/pets",
+		Query: []client.HttpQueryParam{
+			{ Name: "owner", Value: "${{ case[login].Body[id] }}" },
+			{ Name: "tag", Value: "a b" },
+		},
+	}
+	assert.True(t, HasResultReferences(req))
+	r, err := cache.Apply(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", r.Query[0].Value)
+	assert.Equal(t, "${{ case[login].Body[id] }}", req.Query[0].Value)
+	assert.Equal(t, "http://localhost/pets?owner=u1&tag=a+b", client.BuildUrl(r))
+}
@@ -0,0 +1,113 @@
+package sieve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+// the captured results of a run (by the test suite paths & the store ids), e.g. to resolve the references in gen curl
+type ResultStore struct {
+	Suites map[string]map[string]*StoredResult `json:"suites"`
+}
+
+type StoredResult struct {
+	Status string `json:"status"`
+	StatusCode int `json:"status-code"`
+	Header http.Header `json:"headers"`
+	Body string `json:"body"`
+}
+
+func NewResultStore() *ResultStore {
+	return &ResultStore{ Suites: make(map[string]map[string]*StoredResult) }
+}
+
+func LoadResultStore(filePath string) (*ResultStore, error) {
+	file, err := storage.GetFs().Open(filePath)
+	if file != nil {
+		defer file.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open the results file [%s], error: %s", filePath, err)
+	}
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the results file [%s], error: %s", filePath, err)
+	}
+	store := NewResultStore()
+	if err := json.Unmarshal(content, store); err != nil {
+		return nil, fmt.Errorf("Invalid results file [%s], error: %s", filePath, err)
+	}
+	if store.Suites == nil {
+		store.Suites = make(map[string]map[string]*StoredResult)
+	}
+	return store, nil
+}
+
+func (r *ResultStore) Save(filePath string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := storage.GetFs().Create(filePath)
+	if err != nil {
+		return fmt.Errorf("Cannot create the results file [%s], error: %s", filePath, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(content, '\n'))
+	return err
+}
+
+// keeps the captured results of a test suite
+func (r *ResultStore) Collect(suitePath string, cache *RestCache) {
+	if cache == nil {
+		return
+	}
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	if len(cache.restResult) == 0 {
+		return
+	}
+	results := make(map[string]*StoredResult, len(cache.restResult))
+	for testId, rr := range cache.restResult {
+		results[testId] = &StoredResult{
+			Status: rr.Status,
+			StatusCode: rr.StatusCode,
+			Header: rr.Header,
+			Body: string(rr.Body),
+		}
+	}
+	r.Suites[suiteKey(suitePath)] = results
+}
+
+// stores the captured results of a test suite into the cache
+func (r *ResultStore) Restore(suitePath string, cache *RestCache) error {
+	for testId, result := range r.Suites[suiteKey(suitePath)] {
+		if result == nil {
+			continue
+		}
+		_, err := cache.Store(testId, &client.HttpResponse{
+			Status: result.Status,
+			StatusCode: result.StatusCode,
+			Header: result.Header,
+			ContentLength: int64(len(result.Body)),
+			Body: []byte(result.Body),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the test suites are identified by their absolute paths
+func suiteKey(suitePath string) string {
+	if absPath, err := filepath.Abs(suitePath); err == nil {
+		return absPath
+	}
+	return suitePath
+}
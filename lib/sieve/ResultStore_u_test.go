@@ -0,0 +1,45 @@
+package sieve
+
+import(
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestResultStore_SaveAndRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-results")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	header := http.Header{}
+	header.Set("Location", "/v1/users/u1")
+	cache, _ := NewRestCache()
+	_, err = cache.Store("create-user", &client.HttpResponse{ StatusCode: 201, Header: header, Body: []byte(`{ "id": "u1" }`) })
+	assert.Nil(t, err)
+
+	store := NewResultStore()
+	store.Collect("/tests/users.yml", cache)
+	emptyCache, _ := NewRestCache()
+	store.Collect("/tests/health.yml", emptyCache)
+	resultsPath := filepath.Join(dir, "results.json")
+	assert.Nil(t, store.Save(resultsPath))
+
+	loaded, err := LoadResultStore(resultsPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(loaded.Suites))
+	restored, _ := NewRestCache()
+	assert.Nil(t, loaded.Restore("/tests/users.yml", restored))
+	assert.Equal(t, "u1", restored.Evaluate("${{ case[create-user].Body[id] }}"))
+	assert.Equal(t, "/v1/users/u1", restored.Evaluate("${{ case[create-user].Header[Location] }}"))
+
+	other, _ := NewRestCache()
+	assert.Nil(t, loaded.Restore("/tests/orders.yml", other))
+	assert.Equal(t, "${{ case[create-user].Body[id] }}", other.Evaluate("${{ case[create-user].Body[id] }}"))
+
+	_, err = LoadResultStore(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+}
@@ -24,6 +24,9 @@ type File interface {
 
 type Fs interface {
 	Open(name string) (File, error)
+	Create(name string) (File, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Chmod(name string, mode os.FileMode) error
 	Stat(name string) (os.FileInfo, error)
 	IsNotExist(err error) bool
 	Getwd() (dir string, err error)
@@ -36,6 +39,18 @@ func (fs *OsFs) Open(name string) (File, error) {
 	return os.Open(name)
 }
 
+func (fs *OsFs) Create(name string) (File, error) {
+	return os.Create(name)
+}
+
+func (fs *OsFs) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (fs *OsFs) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
 func (fs *OsFs) Stat(name string) (os.FileInfo, error) {
 	return os.Stat(name)
 }
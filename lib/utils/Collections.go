@@ -61,4 +61,12 @@ func Split(str string, sep string) []string {
 		return len(s) > 0
 	})
 	return arr
-}
\ No newline at end of file
+}
+
+func SplitAll(list []string, sep string) []string {
+	arr := make([]string, 0)
+	for _, str := range list {
+		arr = append(arr, Split(str, sep)...)
+	}
+	return arr
+}
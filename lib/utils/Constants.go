@@ -11,13 +11,24 @@ const BODY_FORMAT_FLAT = `text`
 const BODY_FORMAT_JSON = `json`
 const BODY_FORMAT_YAML = `yaml`
 
+const DEFAULT_CONFIG_FILENAME string = `.opwire-testa.yml`
+
+const DEFAULT_ENV_DIRNAME string = `envs`
+
 const DEFAULT_PDP string = `http://localhost:17779`
 const DEFAULT_PATH string = `/-`
 
+const ENV_NAME_PATTERN string = `[a-zA-Z][a-zA-Z0-9_-]*`
+var ENV_NAME_REGEXP *regexp.Regexp = regexp.MustCompile(`^` + ENV_NAME_PATTERN + `$`)
+const ENV_VAR_PATTERN string = `[a-zA-Z_][a-zA-Z0-9_]*`
+
 const TAG_CHAR_PATTERN string = `[^a-zA-Z0-9_-]`
 const TAG_PATTERN string = `[a-zA-Z][a-zA-Z0-9]*([_-][a-zA-Z0-9]*)*`
 const TIME_RFC3339 string = `([0-9]+)-(0[1-9]|1[012])-(0[1-9]|[12][0-9]|3[01])[Tt]([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9]|60)(\\.[0-9]+)?(([Zz])|([\\+|\\-]([01][0-9]|2[0-3]):[0-5][0-9]))`
 const TIMEOUT_PATTERN string = `([0-9]+h)?([0-9]+m)?([0-9]+s)?([0-9]+ms)?([0-9]+[uµ]s)?([0-9]+ns)?`
+const REDIRECTS_PATTERN string = `(follow|none|[0-9]+)`
+// the token characters of RFC 7230, the backtick is escaped
+const HTTP_METHOD_PATTERN string = `[!#$%&'*+.^_\\x60|~0-9A-Za-z-]+`
 
 const TEST_CASE_TITLE_PATTERN string = `[\\p{L}a-zA-Z][\\p{L}\\w\\-\\s.:;,\\{\\}\\[\\]\\(\\)]*`
 var TEST_CASE_TITLE_REGEXP *regexp.Regexp = regexp.MustCompile(`^` + strings.ReplaceAll(TEST_CASE_TITLE_PATTERN, `\\`, `\`) + `$`)
@@ -0,0 +1,36 @@
+package utils
+
+// lists the removed ("- ") and added ("+ ") lines between two texts, based on their longest common subsequence
+func DiffLines(a []string, b []string) []string {
+	lcs := make([][]int, len(a) + 1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b) + 1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i + 1][j + 1] + 1
+			} else if lcs[i + 1][j] >= lcs[i][j + 1] {
+				lcs[i][j] = lcs[i + 1][j]
+			} else {
+				lcs[i][j] = lcs[i][j + 1]
+			}
+		}
+	}
+	diff := make([]string, 0)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i + 1][j] >= lcs[i][j + 1]):
+			diff = append(diff, "- " + a[i])
+			i++
+		default:
+			diff = append(diff, "+ " + b[j])
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,11 @@
+package utils
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, []string{ "- b", "+ x", "+ d" }, DiffLines([]string{ "a", "b", "c" }, []string{ "a", "x", "c", "d" }))
+	assert.Equal(t, []string{}, DiffLines([]string{ "a" }, []string{ "a" }))
+}
@@ -15,3 +15,27 @@ func Unmarshal(format string, source []byte, target interface{}) error {
 	}
 	return fmt.Errorf("Invalid body format: %s", format)
 }
+
+func NormalizeMaps(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = NormalizeMaps(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = NormalizeMaps(item)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = NormalizeMaps(item)
+		}
+		return list
+	}
+	return val
+}
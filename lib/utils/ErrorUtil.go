@@ -2,7 +2,6 @@ package utils
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 )
 
@@ -22,7 +21,7 @@ func CombineErrors(label string, messages []string) error {
 	}
 	if len(errstrs) > 0 {
 		errstrs = append([]string {label}, errstrs...)
-		return fmt.Errorf(strings.Join(errstrs, "\n - "))
+		return errors.New(strings.Join(errstrs, "\n - "))
 	}
 	return nil
 }
@@ -0,0 +1,17 @@
+package utils
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineErrors(t *testing.T) {
+	t.Run("Messages are not formatted", func(t *testing.T) {
+		err := CombineErrors("Invalid body", []string{ "", "Must be less than 100%", "Does not match %s" })
+		assert.Equal(t, "Invalid body\n - Must be less than 100%\n - Does not match %s", err.Error())
+	})
+
+	t.Run("No messages", func(t *testing.T) {
+		assert.Nil(t, CombineErrors("Invalid body", []string{ "" }))
+	})
+}
@@ -14,6 +14,21 @@ func IsDir(name string) bool {
 	return false
 }
 
+func IsFile(name string) bool {
+	fs := storage.GetFs()
+	if stat, err := fs.Stat(name); err == nil {
+		return !stat.IsDir()
+	}
+	return false
+}
+
+func ResolvePath(baseDir string, p string) string {
+	if len(p) == 0 || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(baseDir, p)
+}
+
 func FindWorkingDir() string {
 	fs := storage.GetFs()
 	dir, err := fs.Getwd()
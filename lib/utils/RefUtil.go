@@ -7,3 +7,7 @@ func RefOfInt(val int) *int {
 func RefOfString(val string) *string {
 	return &val
 }
+
+func RefOfBool(val bool) *bool {
+	return &val
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 const VERSION_PATTERN string = `[v]?((\\d+\\.)?(\\d+\\.)?(\\*|\\d+))`
@@ -26,6 +27,18 @@ func StandardizeTagLabel(tag string) (string, error) {
 	return tag, nil
 }
 
+var titleCharRe = regexp.MustCompile(`[^\p{L}\w\-\s.:;,{}\[\]()]+`)
+
+var spacesRe = regexp.MustCompile(`\s+`)
+
+func StandardizeTestCaseTitle(title string) string {
+	title = spacesRe.ReplaceAllString(titleCharRe.ReplaceAllString(title, " "), " ")
+	title = strings.TrimLeftFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.TrimSpace(title)
+}
+
 func ConvertTabToSpaces(block string, newIndent int) string {
 	lines := strings.Split(block, "\n")
 	// determines the indent length
@@ -95,3 +95,9 @@ func TestStandardizeTagLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestStandardizeTestCaseTitle(t *testing.T) {
+	assert.Equal(t, "GET pets {id} - Find a pet", StandardizeTestCaseTitle("GET /pets/{id} - Find a pet"))
+	assert.Equal(t, "users list", StandardizeTestCaseTitle("/users?list"))
+	assert.True(t, TEST_CASE_TITLE_REGEXP.MatchString(StandardizeTestCaseTitle("POST /api/v1/user's_profile")))
+}
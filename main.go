@@ -4,12 +4,14 @@ import(
 	"fmt"
 	"os"
 	"github.com/opwire/opwire-testa/cli"
+	"github.com/opwire/opwire-testa/lib/bootstrap"
 )
 
 func main() {
 	defer func() {
 		if err := recover(); err != nil {
 			fmt.Printf("Testing execution has cracked, error: %s\n", err)
+			os.Exit(bootstrap.EXIT_CODE_CRACKED)
 		}
 	}()
 
@@ -18,12 +20,14 @@ func main() {
 	cmd, err := cli.NewCommander(manifest)
 	if err != nil {
 		fmt.Printf("Cannot create Commander, error: %s\n", err.Error())
-		os.Exit(2)
+		os.Exit(bootstrap.EXIT_CODE_INVALID)
 	}
 
+	// the ExitErrors are ExitCoders of urfave/cli, which prints them & exits with their codes in Run()
 	err = cmd.Run()
 	if err != nil {
+		// the flag parsing & setup errors are not the test failures
 		fmt.Printf("Cannot process command, error: %s\n", err.Error())
-		os.Exit(1)
+		os.Exit(bootstrap.EXIT_CODE_INVALID)
 	}
 }